@@ -0,0 +1,84 @@
+// Package base58 implements the Bitcoin base58 encoding, which the
+// standard library lacks. It shows up wherever CTFs borrow from
+// cryptocurrency: wallet addresses, WIF keys and IPFS hashes.
+package base58
+
+import "fmt"
+
+// Alphabet is the Bitcoin alphabet: digits and letters without the easily
+// confused 0, O, I and l.
+const Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var index = func() [256]int8 {
+	var idx [256]int8
+	for i := range idx {
+		idx[i] = -1
+	}
+	for i := 0; i < len(Alphabet); i++ {
+		idx[Alphabet[i]] = int8(i)
+	}
+	return idx
+}()
+
+// Encode returns the base58 form of data. Each leading zero byte becomes a
+// leading '1', so the encoding round-trips exactly.
+func Encode(data []byte) string {
+	zeros := 0
+	for zeros < len(data) && data[zeros] == 0 {
+		zeros++
+	}
+	// log(256)/log(58) is about 1.37, so this is always enough room.
+	digits := make([]byte, 0, len(data)*138/100+1)
+	for _, b := range data[zeros:] {
+		carry := int(b)
+		for i := range digits {
+			carry += int(digits[i]) << 8
+			digits[i] = byte(carry % 58)
+			carry /= 58
+		}
+		for carry > 0 {
+			digits = append(digits, byte(carry%58))
+			carry /= 58
+		}
+	}
+	out := make([]byte, zeros+len(digits))
+	for i := 0; i < zeros; i++ {
+		out[i] = '1'
+	}
+	for i, d := range digits {
+		out[len(out)-1-i] = Alphabet[d]
+	}
+	return string(out)
+}
+
+// Decode parses a base58 string. Characters outside Alphabet, including
+// whitespace, are rejected with their offset.
+func Decode(s string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(s) && s[zeros] == '1' {
+		zeros++
+	}
+	// log(58)/log(256) is about 0.733.
+	bytes := make([]byte, 0, len(s)*733/1000+1)
+	for i := zeros; i < len(s); i++ {
+		v := index[s[i]]
+		if v < 0 {
+			return nil, fmt.Errorf("base58: invalid character %q at byte %d", s[i], i)
+		}
+		carry := int(v)
+		for j := range bytes {
+			carry += int(bytes[j]) * 58
+			bytes[j] = byte(carry)
+			carry >>= 8
+		}
+		for carry > 0 {
+			bytes = append(bytes, byte(carry))
+			carry >>= 8
+		}
+	}
+	out := make([]byte, zeros+len(bytes))
+	for i, b := range bytes {
+		out[len(out)-1-i] = b
+	}
+	return out, nil
+}
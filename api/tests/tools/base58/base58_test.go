@@ -0,0 +1,64 @@
+package base58
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		data []byte
+		want string
+	}{
+		{nil, ""},
+		{[]byte{0}, "1"},
+		{[]byte{0, 0, 1}, "112"},
+		{[]byte("hello world"), "StV1DL6CwTryKyV"},
+		{[]byte("flag{b4se58}"), "2w6zoHymke2mEzXwJ"},
+	} {
+		got := Encode(tc.data)
+		if got != tc.want {
+			t.Errorf("Encode(%q) = %q, want %q", tc.data, got, tc.want)
+		}
+		back, err := Decode(got)
+		if err != nil || !bytes.Equal(back, tc.data) {
+			t.Errorf("Decode(%q) = %q, %v; want %q", got, back, err, tc.data)
+		}
+	}
+}
+
+func TestDecodeBase58Check(t *testing.T) {
+	// The genesis block's coinbase address: a version byte, a 20-byte
+	// HASH160 and the first 4 bytes of the double SHA-256 of them.
+	raw, err := Decode("1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(raw) != 25 || raw[0] != 0 {
+		t.Fatalf("decoded %x, want 25 bytes behind a zero version byte", raw)
+	}
+	if got, want := hex.EncodeToString(raw[1:21]), "62e907b15cbf27d5425399ebf6f0fb50ebb88f18"; got != want {
+		t.Errorf("hash160 = %s, want %s", got, want)
+	}
+	first := sha256.Sum256(raw[:21])
+	sum := sha256.Sum256(first[:])
+	if !bytes.Equal(raw[21:], sum[:4]) {
+		t.Errorf("checksum %x, want %x", raw[21:], sum[:4])
+	}
+}
+
+func TestDecodeRejectsOutsideAlphabet(t *testing.T) {
+	for _, tc := range []struct{ in, want string }{
+		{"StV1D0", `base58: invalid character '0' at byte 5`},
+		{"OStV", `base58: invalid character 'O' at byte 0`},
+		{"11l", `base58: invalid character 'l' at byte 2`},
+		{"St V", `base58: invalid character ' ' at byte 2`},
+	} {
+		_, err := Decode(tc.in)
+		if err == nil || err.Error() != tc.want {
+			t.Errorf("Decode(%q) error = %v, want %q", tc.in, err, tc.want)
+		}
+	}
+}
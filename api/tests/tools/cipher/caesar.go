@@ -0,0 +1,30 @@
+package cipher
+
+import "fmt"
+
+// Caesar rotates the ASCII letters of s by shift positions, preserving case.
+// Other bytes pass through untouched. Shifts are taken modulo 26, so
+// negative values rotate backwards and Caesar(s, 13) is ROT13.
+func Caesar(s string, shift int) string {
+	shift = ((shift % 26) + 26) % 26
+	out := []byte(s)
+	for i, c := range out {
+		switch {
+		case c >= 'a' && c <= 'z':
+			out[i] = 'a' + (c-'a'+byte(shift))%26
+		case c >= 'A' && c <= 'Z':
+			out[i] = 'A' + (c-'A'+byte(shift))%26
+		}
+	}
+	return string(out)
+}
+
+// CaesarBruteForce returns all 26 rotations of s, each prefixed with its
+// shift as "NN: ", so the entry at index i is s shifted by i.
+func CaesarBruteForce(s string) []string {
+	out := make([]string, 26)
+	for shift := range out {
+		out[shift] = fmt.Sprintf("%2d: %s", shift, Caesar(s, shift))
+	}
+	return out
+}
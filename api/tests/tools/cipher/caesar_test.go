@@ -0,0 +1,40 @@
+package cipher
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCaesarROT13RoundTrip(t *testing.T) {
+	plain := "Flag{Hail_Caesar-2024}"
+	enc := Caesar(plain, 13)
+	if enc != "Synt{Unvy_Pnrfne-2024}" {
+		t.Fatalf("Caesar(13) = %q", enc)
+	}
+	if got := Caesar(enc, 13); got != plain {
+		t.Fatalf("ROT13 twice = %q, want %q", got, plain)
+	}
+}
+
+func TestCaesarNormalizesShift(t *testing.T) {
+	for _, shift := range []int{-3, 23, 49, -29} {
+		if got := Caesar("abc XYZ", shift); got != "xyz UVW" {
+			t.Errorf("Caesar(%d) = %q, want %q", shift, got, "xyz UVW")
+		}
+	}
+}
+
+func TestCaesarBruteForceIndex(t *testing.T) {
+	enc := Caesar("meet me at the docks", 3)
+	got := CaesarBruteForce(enc)
+	if len(got) != 26 {
+		t.Fatalf("got %d rotations, want 26", len(got))
+	}
+	// Undoing a shift of 3 takes 23 more.
+	if want := "23: meet me at the docks"; got[23] != want {
+		t.Fatalf("rotation 23 = %q, want %q", got[23], want)
+	}
+	if !strings.HasPrefix(got[0], " 0: ") {
+		t.Fatalf("rotation 0 = %q, want the \" 0: \" prefix", got[0])
+	}
+}
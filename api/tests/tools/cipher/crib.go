@@ -0,0 +1,99 @@
+package cipher
+
+import (
+	"math"
+	"sort"
+)
+
+// maxCribPeriod is the longest repeating key CribXOR tries to line a key
+// segment up with.
+const maxCribPeriod = 32
+
+// CribMatch is a place where a known plaintext fragment lines up with the
+// ciphertext to give a plausible key segment. Period is the key length the
+// segment decrypts the rest of the ciphertext best under, and Score rates
+// that decryption; lower is better.
+type CribMatch struct {
+	Offset int
+	Key    []byte
+	Period int
+	Score  float64
+	text   bool
+}
+
+// CribXOR drags crib across ciphertext and returns every offset where
+// crib XOR ciphertext is printable ASCII. For a repeating key of length n,
+// Key holds key bytes Offset%n onwards, which is usually enough to guess
+// the rest of a textual key.
+//
+// Printable segments turn up at most offsets, so each one is also tried as
+// part of a repeating key of every period from len(crib) to maxCribPeriod:
+// the real segment, at the real period, decrypts the bytes a period away
+// into English as well. Periods whose decryption is all printable text win.
+// Among those, a decrypted English letter adds well under 1 to
+// ChiSquaredEnglish and a wrong one well over, so each period is scored as
+// the chi-squared of what it decrypts minus the number of bytes: the more
+// ciphertext a segment turns into text, the lower. Matches are returned
+// best first.
+func CribXOR(ciphertext, crib []byte) []CribMatch {
+	var matches []CribMatch
+	if len(crib) == 0 {
+		return nil
+	}
+	for off := 0; off+len(crib) <= len(ciphertext); off++ {
+		key := XORBytes(ciphertext[off:off+len(crib)], crib)
+		if !isPrintable(key) {
+			continue
+		}
+		m := CribMatch{Offset: off, Key: key, Score: math.Inf(1)}
+		for period := len(crib); period <= maxCribPeriod; period++ {
+			sample := extend(ciphertext, key, off, period)
+			if len(sample) < len(crib) {
+				continue
+			}
+			fit := CribMatch{Period: period, Score: ChiSquaredEnglish(sample) - float64(len(sample)), text: isText(sample)}
+			if m.Period == 0 || fit.better(m) {
+				m.Period, m.Score, m.text = fit.Period, fit.Score, fit.text
+			}
+		}
+		matches = append(matches, m)
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].better(matches[j]) })
+	return matches
+}
+
+// extend decrypts the bytes of ciphertext outside the crib at off that key
+// covers if it repeats every period bytes.
+func extend(ciphertext, key []byte, off, period int) []byte {
+	var out []byte
+	for j, c := range ciphertext {
+		if j >= off && j < off+len(key) {
+			continue
+		}
+		if i := ((j-off)%period + period) % period; i < len(key) {
+			out = append(out, c^key[i])
+		}
+	}
+	return out
+}
+
+// better reports whether m ranks ahead of n: matches with no period that
+// fits sort last.
+func (m CribMatch) better(n CribMatch) bool {
+	if (m.Period == 0) != (n.Period == 0) {
+		return m.Period != 0
+	}
+	if m.text != n.text {
+		return m.text
+	}
+	return m.Score < n.Score
+}
+
+func isPrintable(b []byte) bool {
+	for _, c := range b {
+		if c < 0x20 || c >= 0x7f {
+			return false
+		}
+	}
+	return true
+}
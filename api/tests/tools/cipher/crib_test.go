@@ -0,0 +1,35 @@
+package cipher
+
+import "testing"
+
+func TestCribXORRecoversKeySegment(t *testing.T) {
+	plain := []byte("here it is: flag{cr1b_dr4gging} ok.")
+	ct := XORBytes(plain, []byte("secret"))
+
+	matches := CribXOR(ct, []byte("flag{"))
+	if len(matches) == 0 {
+		t.Fatal("CribXOR found no matches")
+	}
+	top := matches[0]
+	if top.Offset != 12 || string(top.Key) != "secre" {
+		t.Fatalf("top match = offset %d key %q, want offset 12 key \"secre\"; all: %v", top.Offset, top.Key, matches)
+	}
+	if top.Period%6 != 0 {
+		t.Errorf("top match period = %d, want a multiple of the key length 6", top.Period)
+	}
+	// Offset 12 is key position 0, so the segment is the key's prefix and
+	// decrypts every other period too.
+	key := append(append([]byte{}, top.Key...), 't')
+	if got := XORBytes(ct, key); string(got) != string(plain) {
+		t.Errorf("completing the key gives %q", got)
+	}
+}
+
+func TestCribXORNoCrib(t *testing.T) {
+	if got := CribXOR([]byte("abc"), nil); got != nil {
+		t.Fatalf("CribXOR(empty crib) = %v, want nil", got)
+	}
+	if got := CribXOR([]byte("ab"), []byte("abc")); len(got) != 0 {
+		t.Fatalf("CribXOR(crib longer than ciphertext) = %v, want none", got)
+	}
+}
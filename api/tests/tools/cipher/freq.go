@@ -0,0 +1,66 @@
+package cipher
+
+import "math"
+
+// englishFreq holds approximate relative frequencies (percent) of the
+// letters a-z in English text.
+var englishFreq = map[byte]float64{
+	'e': 12.7, 't': 9.1, 'a': 8.2, 'o': 7.5, 'i': 7.0, 'n': 6.7, 's': 6.3,
+	'h': 6.1, 'r': 6.0, 'd': 4.3, 'l': 4.0, 'c': 2.8, 'u': 2.8, 'm': 2.4,
+	'w': 2.4, 'f': 2.2, 'g': 2.0, 'y': 2.0, 'p': 1.9, 'b': 1.5, 'v': 1.0,
+	'k': 0.8, 'j': 0.15, 'x': 0.15, 'q': 0.1, 'z': 0.07,
+}
+
+// isText reports whether data holds only printable ASCII and whitespace.
+// ChiSquaredEnglish looks at letters alone, so callers ranking candidate
+// decryptions put the ones that fail this after every one that passes.
+func isText(data []byte) bool {
+	for _, c := range data {
+		if (c < 0x20 && c != '\n' && c != '\r' && c != '\t') || c >= 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+// FrequencyCount counts the ASCII letters in data, folding upper case into
+// lower case. Non-letters are ignored.
+func FrequencyCount(data []byte) map[byte]int {
+	counts := make(map[byte]int)
+	for _, c := range data {
+		if c >= 'A' && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		if c >= 'a' && c <= 'z' {
+			counts[c]++
+		}
+	}
+	return counts
+}
+
+// ChiSquaredEnglish measures how far the letter distribution of data is
+// from English. Lower is a better fit, so candidate decryptions sort
+// ascending. Letters are folded to lower case and everything else is
+// ignored, so only the mix of letters counts, not how many of them there
+// are among other bytes. Data without letters scores +Inf.
+func ChiSquaredEnglish(data []byte) float64 {
+	counts := FrequencyCount(data)
+	var n int
+	for _, c := range counts {
+		n += c
+	}
+	if n == 0 {
+		return math.Inf(1)
+	}
+	var total float64
+	for c := byte('a'); c <= 'z'; c++ {
+		total += englishFreq[c]
+	}
+	var chi float64
+	for c := byte('a'); c <= 'z'; c++ {
+		expected := float64(n) * englishFreq[c] / total
+		d := float64(counts[c]) - expected
+		chi += d * d / expected
+	}
+	return chi
+}
@@ -0,0 +1,52 @@
+package cipher
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+const paragraph = `Frequency analysis works because natural language is anything but
+uniform. In English the letter e turns up about one time in eight, while
+letters such as q, x and z are rare enough that a page of text may hold
+only a handful of them. A substitution cipher hides which symbol stands for
+which letter, but it cannot hide how often each symbol appears.`
+
+func TestFrequencyCount(t *testing.T) {
+	got := FrequencyCount([]byte("Hello, World! 123"))
+	want := map[byte]int{'h': 1, 'e': 1, 'l': 3, 'o': 2, 'w': 1, 'r': 1, 'd': 1}
+	if len(got) != len(want) {
+		t.Fatalf("FrequencyCount = %v, want %v", got, want)
+	}
+	for c, n := range want {
+		if got[c] != n {
+			t.Errorf("count[%c] = %d, want %d", c, got[c], n)
+		}
+	}
+}
+
+func TestChiSquaredEnglish(t *testing.T) {
+	english := ChiSquaredEnglish([]byte(paragraph))
+
+	rng := rand.New(rand.NewSource(1))
+	random := make([]byte, len(paragraph))
+	rng.Read(random)
+	noise := ChiSquaredEnglish(random)
+
+	if english*10 > noise {
+		t.Fatalf("chi-squared of English = %.1f, random bytes = %.1f; want English at least 10x lower", english, noise)
+	}
+	for _, data := range []string{"", "1234 !?"} {
+		if got := ChiSquaredEnglish([]byte(data)); !math.IsInf(got, 1) {
+			t.Fatalf("ChiSquaredEnglish(%q) = %v, want +Inf", data, got)
+		}
+	}
+}
+
+func TestChiSquaredEnglishIgnoresNonLetters(t *testing.T) {
+	a := ChiSquaredEnglish([]byte("Attack at dawn"))
+	b := ChiSquaredEnglish([]byte("attack\x00AT\xff dawn!!! 1234\n"))
+	if a != b {
+		t.Fatalf("same letters scored %v and %v, want equal", a, b)
+	}
+}
@@ -0,0 +1,117 @@
+package cipher
+
+import (
+	"math/bits"
+	"sort"
+)
+
+// keySizeCandidates is how many of the best-scoring key sizes
+// BreakRepeatingXOR fully solves, along with their divisors.
+const keySizeCandidates = 3
+
+// keyAgreement is the fraction of the best key's bytes a divisor's key,
+// repeated, must match for the shorter key to be taken instead.
+const keyAgreement = 0.5
+
+// hamming counts the differing bits between a and b, which must be the
+// same length.
+func hamming(a, b []byte) int {
+	n := 0
+	for i := range a {
+		n += bits.OnesCount8(a[i] ^ b[i])
+	}
+	return n
+}
+
+// keySizeDistance is the Hamming distance between consecutive size-byte
+// blocks of data, averaged over every pair and divided by size. Blocks
+// XORed with the same key bytes differ about as much as the plaintexts do,
+// so the true key size and its multiples score lowest.
+func keySizeDistance(data []byte, size int) float64 {
+	var total, pairs int
+	for off := 0; off+2*size <= len(data); off += size {
+		total += hamming(data[off:off+size], data[off+size:off+2*size])
+		pairs++
+	}
+	return float64(total) / float64(pairs*size)
+}
+
+// solveKey transposes data into size columns, one per key byte, and solves
+// each as single-byte XOR.
+func solveKey(data []byte, size int) []byte {
+	key := make([]byte, size)
+	col := make([]byte, 0, len(data)/size+1)
+	for i := range key {
+		col = col[:0]
+		for j := i; j < len(data); j += size {
+			col = append(col, data[j])
+		}
+		key[i] = BruteForceSingleByte(col)[0].Key
+	}
+	return key
+}
+
+// BreakRepeatingXOR recovers a repeating XOR key between minKey and maxKey
+// bytes long, and the plaintext it decrypts to, from ciphertext alone. Key
+// sizes are ranked by normalised Hamming distance and the best few are
+// solved column by column. Multiples of the true size rank as well as the
+// size itself, and their extra columns overfit the scorer, so divisors are
+// solved too. The key whose plaintext scores best still repeats the true
+// key in most places, so the shortest divisor whose key matches it in at
+// least keyAgreement of its bytes wins. A few hundred bytes of text are
+// enough in practice.
+func BreakRepeatingXOR(ciphertext []byte, minKey, maxKey int) (key, plaintext []byte) {
+	minKey = max(minKey, 1)
+	maxKey = min(maxKey, len(ciphertext)/2)
+	if minKey > maxKey {
+		return nil, nil
+	}
+	sizes := make([]int, 0, maxKey-minKey+1)
+	dist := make(map[int]float64)
+	for size := minKey; size <= maxKey; size++ {
+		sizes = append(sizes, size)
+		dist[size] = keySizeDistance(ciphertext, size)
+	}
+	sort.SliceStable(sizes, func(i, j int) bool { return dist[sizes[i]] < dist[sizes[j]] })
+
+	try := make(map[int]bool)
+	for _, size := range sizes[:min(keySizeCandidates, len(sizes))] {
+		for d := minKey; d <= size; d++ {
+			if size%d == 0 {
+				try[d] = true
+			}
+		}
+	}
+	keys := make(map[int][]byte)
+	var best int
+	var bestFit Candidate
+	for size := minKey; size <= maxKey; size++ {
+		if !try[size] {
+			continue
+		}
+		k := solveKey(ciphertext, size)
+		plain := XORBytes(ciphertext, k)
+		fit := Candidate{Plain: plain, Score: textScore(plain), text: isText(plain)}
+		if best == 0 || fit.better(bestFit) {
+			best, bestFit = size, fit
+		}
+		keys[size] = k
+	}
+	for d := minKey; d <= best; d++ {
+		if try[d] && best%d == 0 && agreement(keys[best], keys[d]) >= keyAgreement {
+			return keys[d], XORBytes(ciphertext, keys[d])
+		}
+	}
+	return nil, nil
+}
+
+// agreement is the fraction of key's bytes that short, repeated, matches.
+func agreement(key, short []byte) float64 {
+	n := 0
+	for i, c := range key {
+		if c == short[i%len(short)] {
+			n++
+		}
+	}
+	return float64(n) / float64(len(key))
+}
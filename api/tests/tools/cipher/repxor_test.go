@@ -0,0 +1,29 @@
+package cipher
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBreakRepeatingXOR(t *testing.T) {
+	for _, key := range []string{"ICE42", "k3y!x", "\x13\x37\xc0\xde\x42"} {
+		ct := XORBytes([]byte(paragraph), []byte(key))
+		gotKey, plain := BreakRepeatingXOR(ct, 2, 40)
+		if string(gotKey) != key {
+			t.Errorf("key %q: recovered %q", key, gotKey)
+		}
+		if !bytes.Equal(plain, []byte(paragraph)) {
+			t.Errorf("key %q: plaintext = %q", key, plain)
+		}
+	}
+}
+
+func TestKeySizeDistanceFavoursTrueSize(t *testing.T) {
+	ct := XORBytes([]byte(longText), []byte("s3cr3t"))
+	want := keySizeDistance(ct, 6)
+	for _, size := range []int{2, 3, 4, 5, 7, 8} {
+		if d := keySizeDistance(ct, size); d <= want {
+			t.Errorf("distance at size %d = %.3f, not above %.3f at the true size", size, d, want)
+		}
+	}
+}
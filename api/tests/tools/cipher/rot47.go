@@ -0,0 +1,21 @@
+package cipher
+
+// ROT47 rotates every byte in the printable range '!'..'~' by 47 places,
+// leaving spaces, newlines and everything else alone. The range holds 94
+// characters, so ROT47 is its own inverse.
+func ROT47(s string) string {
+	return ROTN(s, 47)
+}
+
+// ROTN rotates the printable range '!'..'~' like ROT47 but by n places,
+// taken modulo 94. ROTN(s, -n) undoes ROTN(s, n).
+func ROTN(s string, n int) string {
+	n = ((n % 94) + 94) % 94
+	out := []byte(s)
+	for i, c := range out {
+		if c >= '!' && c <= '~' {
+			out[i] = '!' + byte((int(c-'!')+n)%94)
+		}
+	}
+	return string(out)
+}
@@ -0,0 +1,26 @@
+package cipher
+
+import "testing"
+
+func TestROT47(t *testing.T) {
+	plain := "flag{r0t47 keeps spaces\n}"
+	enc := ROT47(plain)
+	if enc != "7=28LC_Ecf <66AD DA246D\nN" {
+		t.Fatalf("ROT47 = %q", enc)
+	}
+	if got := ROT47(enc); got != plain {
+		t.Fatalf("ROT47 twice = %q, want %q", got, plain)
+	}
+}
+
+func TestROTN(t *testing.T) {
+	plain := "Hello, World!"
+	for _, n := range []int{1, 13, 93, 94, -5, 200} {
+		if got := ROTN(ROTN(plain, n), -n); got != plain {
+			t.Errorf("ROTN(ROTN(s, %d), %d) = %q", n, -n, got)
+		}
+	}
+	if got := ROTN("!~", 1); got != "\"!" {
+		t.Errorf("ROTN(\"!~\", 1) = %q, want the range to wrap", got)
+	}
+}
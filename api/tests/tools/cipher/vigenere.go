@@ -0,0 +1,108 @@
+package cipher
+
+import "sort"
+
+// maxKeyLen bounds the key lengths KasiskiGuessKeyLength considers.
+const maxKeyLen = 20
+
+// randomIoC is the index of coincidence of uniformly random letters.
+const randomIoC = 1.0 / 26
+
+// Vigenere encrypts text with key, or decrypts it when decrypt is set,
+// shifting each ASCII letter by the next key letter and preserving case.
+// Non-letters in text pass through without consuming a key position, and
+// non-letters in key are ignored; a key without letters leaves text as is.
+func Vigenere(text, key string, decrypt bool) string {
+	var shifts []byte
+	for i := 0; i < len(key); i++ {
+		c := key[i] | 0x20
+		if c >= 'a' && c <= 'z' {
+			shift := c - 'a'
+			if decrypt {
+				shift = (26 - shift) % 26
+			}
+			shifts = append(shifts, shift)
+		}
+	}
+	if len(shifts) == 0 {
+		return text
+	}
+	out := []byte(text)
+	k := 0
+	for i, c := range out {
+		switch {
+		case c >= 'a' && c <= 'z':
+			out[i] = 'a' + (c-'a'+shifts[k%len(shifts)])%26
+		case c >= 'A' && c <= 'Z':
+			out[i] = 'A' + (c-'A'+shifts[k%len(shifts)])%26
+		default:
+			continue
+		}
+		k++
+	}
+	return string(out)
+}
+
+// indexOfCoincidence is the chance that two letters drawn from counts
+// without replacement are equal: about 0.067 for English and 0.038 for
+// uniformly random letters.
+func indexOfCoincidence(counts [26]int, n int) float64 {
+	if n < 2 {
+		return 0
+	}
+	var sum int
+	for _, c := range counts {
+		sum += c * (c - 1)
+	}
+	return float64(sum) / float64(n*(n-1))
+}
+
+// KasiskiGuessKeyLength ranks likely Vigenère key lengths for ciphertext,
+// best first. For each length L the letters are split into L columns, each
+// enciphered with a single shift, and the average index of coincidence of
+// the columns is compared: the right length makes every column look like
+// English. Multiples of the true length score as well or, with shorter
+// columns, a little better, so a length is ranked behind any divisor whose
+// excess over random text is at least 80% of its own. Only letters count.
+func KasiskiGuessKeyLength(ciphertext []byte) []int {
+	var letters []byte
+	for _, c := range ciphertext {
+		c |= 0x20
+		if c >= 'a' && c <= 'z' {
+			letters = append(letters, c-'a')
+		}
+	}
+	limit := min(maxKeyLen, len(letters)/2)
+	if limit < 1 {
+		return nil
+	}
+	ioc := make([]float64, limit+1)
+	lengths := make([]int, 0, limit)
+	for l := 1; l <= limit; l++ {
+		var total float64
+		for col := 0; col < l; col++ {
+			var counts [26]int
+			n := 0
+			for i := col; i < len(letters); i += l {
+				counts[letters[i]]++
+				n++
+			}
+			total += indexOfCoincidence(counts, n)
+		}
+		ioc[l] = total / float64(l)
+		lengths = append(lengths, l)
+	}
+	sort.SliceStable(lengths, func(i, j int) bool { return ioc[lengths[i]] > ioc[lengths[j]] })
+
+	ranked := make([]int, 0, limit)
+	seen := make([]bool, limit+1)
+	for _, l := range lengths {
+		for d := 1; d <= l; d++ {
+			if l%d == 0 && !seen[d] && (d == l || ioc[d]-randomIoC >= 0.8*(ioc[l]-randomIoC)) {
+				seen[d] = true
+				ranked = append(ranked, d)
+			}
+		}
+	}
+	return ranked
+}
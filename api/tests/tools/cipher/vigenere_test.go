@@ -0,0 +1,48 @@
+package cipher
+
+import "testing"
+
+// longText is long enough that each column of an 11-letter key still holds
+// about 80 letters.
+const longText = `The Vigenere cipher was long called the indecipherable cipher, and for
+three centuries it earned the name. Each letter of the message is shifted by
+a different amount, taken in turn from the letters of a keyword, so the same
+plaintext letter comes out differently depending on where it falls. Simple
+frequency analysis of the whole ciphertext therefore shows a nearly flat
+distribution, and the usual tricks for breaking a substitution cipher fail.
+The weakness is that the keyword repeats. Every letter that sits a whole
+number of key lengths away from another was shifted by the same amount, so
+if the ciphertext is split into columns by position modulo the key length,
+each column is just a Caesar cipher over ordinary English text. Charles
+Babbage and later Friedrich Kasiski noticed that repeated fragments of the
+plaintext line up with the key at distances that are multiples of its
+length, and the index of coincidence gives a statistical way to test every
+candidate length at once. Once the length is known, each column falls to a
+frequency count and the keyword can be read off one letter at a time.`
+
+func TestVigenereRoundTrip(t *testing.T) {
+	plain := "Attack at Dawn: flag{v1gen3re}!"
+	enc := Vigenere(plain, "LEMON", false)
+	// Digits and punctuation pass through without using up key letters.
+	if want := "Lxfopv ef Rnhr: rznr{z1ssa3ci}!"; enc != want {
+		t.Fatalf("Vigenere(encrypt) = %q, want %q", enc, want)
+	}
+	if got := Vigenere(enc, "lemon", true); got != plain {
+		t.Fatalf("Vigenere(decrypt) = %q, want %q", got, plain)
+	}
+	if got := Vigenere(plain, "123", false); got != plain {
+		t.Fatalf("key without letters changed text to %q", got)
+	}
+}
+
+func TestKasiskiGuessKeyLength(t *testing.T) {
+	// secret and vigenere repeat letters, so a divisor of their length
+	// scores well too.
+	for _, key := range []string{"ab", "lemon", "crypto", "secret", "vigenere", "kasiskiexam"} {
+		enc := Vigenere(longText, key, false)
+		got := KasiskiGuessKeyLength([]byte(enc))
+		if len(got) == 0 || got[0] != len(key) {
+			t.Errorf("key %q: ranked lengths %v, want %d first", key, got, len(key))
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// Package cipher collects the classical and XOR ciphers that keep showing
+// up in CTF crypto rounds.
+package cipher
+
+import (
+	"math"
+	"sort"
+)
+
+// XORBytes XORs data with key, repeating the key cyclically. An empty key
+// returns a copy of data.
+func XORBytes(data, key []byte) []byte {
+	out := make([]byte, len(data))
+	if len(key) == 0 {
+		copy(out, data)
+		return out
+	}
+	for i, c := range data {
+		out[i] = c ^ key[i%len(key)]
+	}
+	return out
+}
+
+// XORSingle XORs every byte of data with b.
+func XORSingle(data []byte, b byte) []byte {
+	return XORBytes(data, []byte{b})
+}
+
+// Candidate is one guess from a brute-force search. Score is the
+// textScore of Plain, so lower is better.
+type Candidate struct {
+	Key   byte
+	Plain []byte
+	Score float64
+	text  bool
+}
+
+// BruteForceSingleByte tries all 256 single-byte keys against data and
+// returns the results ranked from most to least English-looking: plaintexts
+// made only of printable text first, each group by ascending Score.
+func BruteForceSingleByte(data []byte) []Candidate {
+	out := make([]Candidate, 256)
+	for k := 0; k < 256; k++ {
+		plain := XORSingle(data, byte(k))
+		out[k] = Candidate{Key: byte(k), Plain: plain, Score: textScore(plain), text: isText(plain)}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].better(out[j]) })
+	return out
+}
+
+// better reports whether c ranks ahead of d.
+func (c Candidate) better(d Candidate) bool {
+	if c.text != d.text {
+		return c.text
+	}
+	return c.Score < d.Score
+}
+
+// letterShare and spaceShare are the rough fractions of English text made
+// up of letters and of spaces; punctuation, digits and newlines fill the
+// rest.
+const (
+	letterShare = 0.78
+	spaceShare  = 0.16
+)
+
+// textScore rates plain as a candidate decryption; lower is better.
+// ChiSquaredEnglish sees only the mix of letters, so a key that turns a
+// column into punctuation and a stray e would fit as well as English.
+// textScore adds the chi-squared of how plain splits into letters, spaces
+// and other bytes against English's shares, which favours words.
+func textScore(plain []byte) float64 {
+	if len(plain) == 0 {
+		return math.Inf(1)
+	}
+	var letters, spaces int
+	for _, c := range FrequencyCount(plain) {
+		letters += c
+	}
+	for _, c := range plain {
+		if c == ' ' {
+			spaces++
+		}
+	}
+	n := float64(len(plain))
+	term := func(observed int, share float64) float64 {
+		d := float64(observed) - n*share
+		return d * d / (n * share)
+	}
+	return ChiSquaredEnglish(plain) + term(letters, letterShare) + term(spaces, spaceShare) + term(len(plain)-letters-spaces, 1-letterShare-spaceShare)
+}
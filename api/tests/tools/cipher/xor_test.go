@@ -0,0 +1,28 @@
+package cipher
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestXORBytesRoundTrip(t *testing.T) {
+	plain := []byte("attack at dawn")
+	key := []byte("key")
+	if got := XORBytes(XORBytes(plain, key), key); !bytes.Equal(got, plain) {
+		t.Fatalf("round trip = %q, want %q", got, plain)
+	}
+	if got := XORBytes(plain, nil); !bytes.Equal(got, plain) {
+		t.Fatalf("empty key = %q, want a copy of the input", got)
+	}
+}
+
+func TestBruteForceSingleByte(t *testing.T) {
+	plain := []byte("the flag for this round is flag{x0r_is_not_encryption}")
+	cands := BruteForceSingleByte(XORSingle(plain, 0x5a))
+	if len(cands) != 256 {
+		t.Fatalf("got %d candidates, want 256", len(cands))
+	}
+	if top := cands[0]; top.Key != 0x5a || !bytes.Equal(top.Plain, plain) {
+		t.Fatalf("top candidate = key %#x %q, want key 0x5a %q", top.Key, top.Plain, plain)
+	}
+}
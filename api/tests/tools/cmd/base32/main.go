@@ -0,0 +1,82 @@
+// Base32 decodes base32 from stdin, ignoring case, whitespace, dashes and
+// missing padding, or encodes stdin with -e.
+package main
+
+import (
+	"bytes"
+	"encoding/base32"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// clean strips whitespace and dashes and upper-cases s, since TOTP secrets
+// and onion addresses are usually written in lower case and in groups. It
+// returns the remaining characters alongside each one's offset in the
+// original input.
+func clean(s []byte) ([]byte, []int) {
+	out := make([]byte, 0, len(s))
+	offsets := make([]int, 0, len(s))
+	for i, c := range s {
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '-':
+			continue
+		case c >= 'a' && c <= 'z':
+			c -= 'a' - 'A'
+		}
+		out = append(out, c)
+		offsets = append(offsets, i)
+	}
+	return out, offsets
+}
+
+// decode parses base32 with or without '=' padding. On failure it returns
+// what decoded before the bad byte and an error naming its offset in the
+// input.
+func decode(input []byte) ([]byte, error) {
+	s, offsets := clean(input)
+	s = bytes.TrimRight(s, "=")
+	// A last block of 1, 3 or 6 characters can't end on a byte boundary.
+	// encoding/base32 silently decodes nothing from such a block, so decode
+	// all but its last character and report the truncation.
+	truncated := false
+	switch len(s) % 8 {
+	case 1, 3, 6:
+		s, truncated = s[:len(s)-1], true
+	}
+	enc := base32.StdEncoding.WithPadding(base32.NoPadding)
+	out := make([]byte, enc.DecodedLen(len(s)))
+	n, err := enc.Decode(out, s)
+	var corrupt base32.CorruptInputError
+	switch {
+	case errors.As(err, &corrupt) && int(corrupt) < len(s):
+		off := offsets[int(corrupt)]
+		err = fmt.Errorf("invalid character %q at byte %d", input[off], off)
+	case err != nil || truncated:
+		err = fmt.Errorf("truncated input at byte %d", len(input))
+	}
+	return out[:n], err
+}
+
+func main() {
+	encode := flag.Bool("e", false, "encode stdin instead of decoding it")
+	flag.Parse()
+
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "base32: reading stdin: %v\n", err)
+		os.Exit(1)
+	}
+	if *encode {
+		fmt.Print(base32.StdEncoding.EncodeToString(data))
+		return
+	}
+	decoded, err := decode(data)
+	fmt.Print(string(decoded))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "base32: malformed input, output is best effort (%v)\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/base32"
+	"fmt"
+	"testing"
+)
+
+func TestDecodeRoundTrip(t *testing.T) {
+	for _, plain := range []string{"", "f", "fo", "foo", "foob", "fooba", "flag{b4se32_totp}"} {
+		enc := base32.StdEncoding.EncodeToString([]byte(plain))
+		got, err := decode([]byte(enc))
+		if err != nil || string(got) != plain {
+			t.Errorf("decode(%q) = %q, %v; want %q", enc, got, err, plain)
+		}
+	}
+}
+
+func TestDecodeNormalizes(t *testing.T) {
+	for _, in := range []string{
+		"MZWGCZ33MI2HGZJTGJPXI33UOB6Q====",
+		"MZWGCZ33MI2HGZJTGJPXI33UOB6Q",
+		"mzwgcz33mi2hgzjtgjpxi33uob6q",
+		"mzwg-cz33-mi2h-gzjt-gjpx-i33u-ob6q\n",
+		"MZWG CZ33\tMI2H GZJT\r\nGJPX I33U OB6Q",
+	} {
+		got, err := decode([]byte(in))
+		if err != nil || string(got) != "flag{b4se32_totp}" {
+			t.Errorf("decode(%q) = %q, %v", in, got, err)
+		}
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	for _, tc := range []struct{ in, want string }{
+		{"MZWG-CZ1", `invalid character '1' at byte 7`},
+	} {
+		_, err := decode([]byte(tc.in))
+		if err == nil || err.Error() != tc.want {
+			t.Errorf("decode(%q) error = %v, want %q", tc.in, err, tc.want)
+		}
+	}
+}
+
+func TestDecodeTruncatedKeepsPrefix(t *testing.T) {
+	for _, tc := range []struct{ in, want string }{
+		{"M", ""},
+		{"MZW", "f"},
+		{"MZWGCZ", "fla"},
+		{"mzwg-cz33-m\n", "flag{"},
+	} {
+		got, err := decode([]byte(tc.in))
+		if want := fmt.Sprintf("truncated input at byte %d", len(tc.in)); err == nil || err.Error() != want {
+			t.Errorf("decode(%q) error = %v, want %q", tc.in, err, want)
+		}
+		if string(got) != tc.want {
+			t.Errorf("decode(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+// Base58 decodes Bitcoin-alphabet base58 from stdin, or encodes stdin
+// with -e.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dnakov/ctf-arena/api/tests/tools/base58"
+)
+
+func main() {
+	enc := flag.Bool("e", false, "encode stdin instead of decoding it")
+	flag.Parse()
+
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "base58: reading stdin: %v\n", err)
+		os.Exit(1)
+	}
+	if *enc {
+		fmt.Print(base58.Encode(data))
+		return
+	}
+	decoded, err := base58.Decode(strings.TrimSpace(string(data)))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Print(string(decoded))
+}
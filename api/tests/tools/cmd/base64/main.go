@@ -0,0 +1,110 @@
+// Base64 decodes base64 from stdin in whichever alphabet it turns out to
+// use, or encodes stdin with -e.
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+var encodings = []struct {
+	name string
+	enc  *base64.Encoding
+}{
+	{"standard", base64.StdEncoding},
+	{"url-safe", base64.URLEncoding},
+	{"raw standard", base64.RawStdEncoding},
+	{"raw url-safe", base64.RawURLEncoding},
+}
+
+// clean drops every whitespace byte from s, so wrapped and PEM-style input
+// decodes, and records where each kept byte sat in the original input.
+func clean(s []byte) (string, []int) {
+	var sb strings.Builder
+	offsets := make([]int, 0, len(s))
+	for i, c := range s {
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' {
+			continue
+		}
+		sb.WriteByte(c)
+		offsets = append(offsets, i)
+	}
+	return sb.String(), offsets
+}
+
+// decode tries each base64 alphabet in turn, preferring the URL-safe ones
+// when the input contains '-' or '_'. With pad set, input whose length is
+// not a multiple of 4 is padded with '='. If no alphabet decodes cleanly it
+// returns the longest partial result and the preferred alphabet's error,
+// located in the original input.
+func decode(input []byte, pad bool) ([]byte, error) {
+	s, offsets := clean(input)
+	if pad && len(s)%4 != 0 {
+		s += strings.Repeat("=", 4-len(s)%4)
+	}
+	order := []int{0, 1, 2, 3}
+	if strings.ContainsAny(s, "-_") {
+		order = []int{1, 3, 0, 2}
+	}
+	var best []byte
+	var firstErr error
+	for _, i := range order {
+		decoded, err := encodings[i].enc.DecodeString(s)
+		if err == nil {
+			return decoded, nil
+		}
+		if len(decoded) > len(best) {
+			best = decoded
+		}
+		if firstErr == nil {
+			firstErr = fmt.Errorf("%s alphabet: %w", encodings[i].name, locate(err, s, offsets, len(input)))
+		}
+	}
+	return best, firstErr
+}
+
+// locate rewrites a base64.CorruptInputError, which indexes the cleaned
+// string, in terms of the offending byte and its offset in the raw input.
+func locate(err error, s string, offsets []int, size int) error {
+	var ce base64.CorruptInputError
+	if !errors.As(err, &ce) {
+		return err
+	}
+	if n := int(ce); n < len(offsets) {
+		return fmt.Errorf("invalid character %q at byte %d", s[n], offsets[n])
+	}
+	return fmt.Errorf("truncated input at byte %d", size)
+}
+
+// encode returns data in the standard padded alphabet, the form decode
+// tries first.
+func encode(data []byte) string {
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+func main() {
+	enc := flag.Bool("e", false, "encode stdin instead of decoding it")
+	pad := flag.Bool("pad", true, "add missing '=' padding before decoding")
+	flag.Parse()
+
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "base64: reading stdin: %v\n", err)
+		os.Exit(1)
+	}
+	if *enc {
+		fmt.Print(encode(data))
+		return
+	}
+	decoded, err := decode(data, *pad)
+	fmt.Print(string(decoded))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "base64: malformed input, output is best effort (%v)\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestDecodeURLSafeFixture(t *testing.T) {
+	input, err := os.ReadFile("testdata/urlsafe.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := decode(input, true)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if want := "flag{url_s4fe??>>~~}"; string(got) != want {
+		t.Fatalf("decode = %q, want %q", got, want)
+	}
+}
+
+func TestDecodeReportsBadByte(t *testing.T) {
+	got, err := decode([]byte("ZmxhZ3t9\n!!!!"), true)
+	if err == nil {
+		t.Fatalf("decode = %q, want an error", got)
+	}
+	if want := `standard alphabet: invalid character '!' at byte 9`; err.Error() != want {
+		t.Fatalf("error = %q, want %q", err, want)
+	}
+	if string(got) != "flag{}" {
+		t.Fatalf("best effort = %q, want %q", got, "flag{}")
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	for _, data := range [][]byte{
+		[]byte(""),
+		[]byte("flag{encode_me}"),
+		[]byte("line one\nline two\r\n\n"),
+		{0x00, 0xff, '\n', 0xfe, 0x0a, 0x0d, 0x80, '\n'},
+	} {
+		got, err := decode([]byte(encode(data)), true)
+		if err != nil {
+			t.Errorf("decode(encode(%q)): %v", data, err)
+			continue
+		}
+		if string(got) != string(data) {
+			t.Errorf("decode(encode(%q)) = %q", data, got)
+		}
+	}
+}
+
+func TestDecodeWrappedPEMBody(t *testing.T) {
+	want := make([]byte, 100)
+	for i := range want {
+		want[i] = byte(i * 7)
+	}
+	enc := encode(want)
+	var pem []byte
+	for i := 0; i < len(enc); i += 64 {
+		pem = append(pem, enc[i:min(i+64, len(enc))]...)
+		pem = append(pem, "\r\n"...)
+	}
+	got, err := decode(pem, true)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if string(got) != string(want) {
+		t.Fatalf("decode = %x, want %x", got, want)
+	}
+}
+
+func TestDecodeUnpadded(t *testing.T) {
+	for _, in := range []string{"ZmxhZ3t4fQ", "ZmxhZ3t4eX0", "ZmxhZ3t4fQ==\n"} {
+		got, err := decode([]byte(in), true)
+		if err != nil || string(got) == "" {
+			t.Errorf("decode(%q) = %q, %v", in, got, err)
+		}
+	}
+	if got, _ := decode([]byte("ZmxhZ3t4fQ"), true); string(got) != "flag{x}" {
+		t.Errorf("decode(unpadded) = %q, want %q", got, "flag{x}")
+	}
+	if got, err := decode([]byte("Zm9vYg"), false); err != nil || string(got) != "foob" {
+		t.Errorf("decode(-pad=false) = %q, %v; want the raw alphabet to take it", got, err)
+	}
+}
@@ -0,0 +1,130 @@
+// Crackhash finds the word of a wordlist read from stdin whose MD5, SHA-1
+// or SHA-256 digest is the given hash.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto"
+	_ "crypto/md5"
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// algorithms maps -algo names to hashes; the blank imports above register
+// their implementations with package crypto. Adding one is a single entry
+// plus its import.
+var algorithms = map[string]crypto.Hash{
+	"md5":    crypto.MD5,
+	"sha1":   crypto.SHA1,
+	"sha256": crypto.SHA256,
+}
+
+// guessAlgorithm picks the registered algorithm whose digest is size bytes.
+func guessAlgorithm(size int) (string, bool) {
+	for name, h := range algorithms {
+		if h.Size() == size {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// crack hashes each line of words with h from a pool of workers and returns
+// the first whose digest is target. The scan stops as soon as any worker
+// finds it, or when ctx is cancelled.
+func crack(ctx context.Context, h crypto.Hash, target []byte, words io.Reader, workers int) (string, bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan string)
+	var (
+		once  sync.Once
+		found string
+		ok    bool
+		wg    sync.WaitGroup
+	)
+	for i := 0; i < max(workers, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			hash := h.New()
+			for word := range jobs {
+				hash.Reset()
+				hash.Write([]byte(word))
+				if bytes.Equal(hash.Sum(nil), target) {
+					once.Do(func() {
+						found, ok = word, true
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+
+	sc := bufio.NewScanner(words)
+feed:
+	for sc.Scan() {
+		select {
+		case jobs <- strings.TrimRight(sc.Text(), "\r"):
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if ok {
+		return found, true, nil
+	}
+	return "", false, sc.Err()
+}
+
+func main() {
+	target := flag.String("hash", "", "hex digest to crack")
+	algo := flag.String("algo", "", "md5, sha1 or sha256 (default: guessed from the digest length)")
+	workers := flag.Int("workers", runtime.NumCPU(), "concurrent hashing workers")
+	flag.Parse()
+
+	digest, err := hex.DecodeString(strings.TrimSpace(*target))
+	if err != nil || len(digest) == 0 {
+		fmt.Fprintln(os.Stderr, "crackhash: -hash must be a hex digest")
+		os.Exit(2)
+	}
+	if *algo == "" {
+		name, ok := guessAlgorithm(len(digest))
+		if !ok {
+			fmt.Fprintf(os.Stderr, "crackhash: no algorithm has %d-byte digests; pass -algo\n", len(digest))
+			os.Exit(2)
+		}
+		*algo = name
+	}
+	h, ok := algorithms[strings.ToLower(*algo)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "crackhash: unknown algorithm %q\n", *algo)
+		os.Exit(2)
+	}
+	if h.Size() != len(digest) {
+		fmt.Fprintf(os.Stderr, "crackhash: %s digests are %d bytes, -hash is %d\n", *algo, h.Size(), len(digest))
+		os.Exit(2)
+	}
+
+	word, found, err := crack(context.Background(), h, digest, os.Stdin, *workers)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "crackhash: reading wordlist: %v\n", err)
+		os.Exit(1)
+	}
+	if !found {
+		fmt.Fprintln(os.Stderr, "crackhash: not found in wordlist")
+		os.Exit(1)
+	}
+	fmt.Println(word)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"crypto"
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func digest(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestCrackSHA256(t *testing.T) {
+	target := digest(t, "1c8bfe8f801d79745c4631d09fff36c82aa37fc4cce4fc946683d7b336b63032")
+	words := strings.NewReader("123456\npassword\r\nqwerty\nletmein\ndragon\n")
+	word, ok, err := crack(context.Background(), crypto.SHA256, target, words, 3)
+	if err != nil || !ok || word != "letmein" {
+		t.Fatalf("crack = %q, %v, %v; want letmein", word, ok, err)
+	}
+}
+
+func TestCrackMD5CarriageReturns(t *testing.T) {
+	target := digest(t, "2ab96390c7dbe3439de74d0c9b0b1767")
+	words := strings.NewReader("admin\r\nhunter2\r\n")
+	if word, ok, _ := crack(context.Background(), crypto.MD5, target, words, 2); !ok || word != "hunter2" {
+		t.Fatalf("crack = %q, %v; want hunter2", word, ok)
+	}
+}
+
+func TestCrackNotFound(t *testing.T) {
+	target := digest(t, "1c8bfe8f801d79745c4631d09fff36c82aa37fc4cce4fc946683d7b336b63032")
+	word, ok, err := crack(context.Background(), crypto.SHA256, target, strings.NewReader("a\nb\nc\n"), 2)
+	if err != nil || ok || word != "" {
+		t.Fatalf("crack = %q, %v, %v; want not found", word, ok, err)
+	}
+}
+
+// endless yields "nope\n" forever.
+type endless struct{}
+
+func (endless) Read(p []byte) (int, error) {
+	n := 0
+	for n+5 <= len(p) {
+		n += copy(p[n:], "nope\n")
+	}
+	return n, nil
+}
+
+func TestCrackStopsOnMatch(t *testing.T) {
+	target := digest(t, "1c8bfe8f801d79745c4631d09fff36c82aa37fc4cce4fc946683d7b336b63032")
+	words := io.MultiReader(strings.NewReader("letmein\n"), endless{})
+	done := make(chan string)
+	go func() {
+		word, _, _ := crack(context.Background(), crypto.SHA256, target, words, 4)
+		done <- word
+	}()
+	select {
+	case word := <-done:
+		if word != "letmein" {
+			t.Fatalf("crack = %q, want letmein", word)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("crack kept reading the wordlist after a match")
+	}
+}
+
+func TestGuessAlgorithm(t *testing.T) {
+	for size, want := range map[int]string{16: "md5", 20: "sha1", 32: "sha256"} {
+		if got, ok := guessAlgorithm(size); !ok || got != want {
+			t.Errorf("guessAlgorithm(%d) = %q, %v; want %q", size, got, ok, want)
+		}
+	}
+	if _, ok := guessAlgorithm(24); ok {
+		t.Error("guessAlgorithm(24) found an algorithm")
+	}
+}
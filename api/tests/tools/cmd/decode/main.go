@@ -0,0 +1,54 @@
+// Decode peels layered encodings off stdin, stopping as soon as a flag
+// surfaces, and prints what is left with any flags highlighted.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dnakov/ctf-arena/api/tests/tools/decode"
+	"github.com/dnakov/ctf-arena/api/tests/tools/flagfmt"
+)
+
+// peel decodes data until a flag matching pattern shows up or nothing more
+// applies, and returns the result, the transforms applied and the flags
+// found in it.
+func peel(data []byte, rounds int, pattern string) (out []byte, trail, flags []string) {
+	out, trail = decode.AutoDecodeUntil(data, rounds, flagfmt.Found(pattern))
+	return out, trail, flagfmt.FindFlags(out, pattern)
+}
+
+// isTerminal reports whether f is a terminal rather than a pipe or file,
+// so escape codes are only written where they will be rendered.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+func main() {
+	rounds := flag.Int("rounds", 10, "maximum number of layers to peel")
+	pattern := flag.String("pattern", flagfmt.DefaultPattern, "flag regexp to stop at, or a bare prefix such as HTB{")
+	flag.Parse()
+
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "decode: reading stdin: %v\n", err)
+		os.Exit(1)
+	}
+	out, trail, flags := peel(data, *rounds, *pattern)
+	if len(trail) == 0 {
+		fmt.Fprintln(os.Stderr, "decode: no encoding recognised")
+	} else {
+		fmt.Fprintf(os.Stderr, "decode: %s\n", strings.Join(trail, " -> "))
+	}
+	if isTerminal(os.Stdout) {
+		out = flagfmt.Highlight(out, *pattern)
+	}
+	os.Stdout.Write(out)
+	for _, f := range flags {
+		fmt.Fprintf(os.Stderr, "decode: found %s\n", f)
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"reflect"
+	"testing"
+)
+
+func TestPeelStopsAtFlag(t *testing.T) {
+	wrapped := []byte(base64.StdEncoding.EncodeToString([]byte(hex.EncodeToString([]byte("flag{100%25} HTB{0ther}")))))
+	out, trail, flags := peel(wrapped, 10, "")
+	if string(out) != "flag{100%25} HTB{0ther}" {
+		t.Fatalf("peel = %q, want the flags with %%25 left alone", out)
+	}
+	if want := []string{"base64", "hex"}; !reflect.DeepEqual(trail, want) {
+		t.Errorf("trail = %v, want %v", trail, want)
+	}
+	if want := []string{"flag{100%25}", "HTB{0ther}"}; !reflect.DeepEqual(flags, want) {
+		t.Errorf("flags = %q, want %q", flags, want)
+	}
+}
+
+func TestPeelCustomPrefix(t *testing.T) {
+	wrapped := []byte(hex.EncodeToString([]byte("decoy{x} HTB{r3al}")))
+	_, _, flags := peel(wrapped, 10, "HTB{")
+	if want := []string{"HTB{r3al}"}; !reflect.DeepEqual(flags, want) {
+		t.Fatalf("flags with prefix HTB{ = %q, want %q", flags, want)
+	}
+}
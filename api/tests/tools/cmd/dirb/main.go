@@ -0,0 +1,160 @@
+// Dirb requests each word of a wordlist read from stdin, optionally with
+// extensions, as a path under a base URL and reports the status codes.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// interesting status codes are highlighted in the output.
+var interesting = map[int]bool{200: true, 301: true, 302: true, 403: true}
+
+// headers collects repeated -H "Name: value" flags.
+type headers http.Header
+
+func (h headers) String() string { return "" }
+
+func (h headers) Set(s string) error {
+	name, value, ok := strings.Cut(s, ":")
+	if !ok || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("header %q is not Name: value", s)
+	}
+	http.Header(h).Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	return nil
+}
+
+// hit is the response to one requested path.
+type hit struct {
+	url      string
+	status   int
+	size     int64
+	location string
+}
+
+// paths expands each word of the wordlist into itself and one path per
+// extension, skipping blank lines and # comments.
+func paths(words io.Reader, exts []string, fn func(path string)) error {
+	sc := bufio.NewScanner(words)
+	for sc.Scan() {
+		word := strings.TrimSpace(sc.Text())
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		word = strings.TrimPrefix(word, "/")
+		fn(word)
+		for _, ext := range exts {
+			fn(word + "." + strings.TrimPrefix(ext, "."))
+		}
+	}
+	return sc.Err()
+}
+
+// fetch GETs url with the headers in hdr and reports its status, body size
+// and any redirect target.
+func fetch(client *http.Client, url string, hdr http.Header) (hit, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return hit{}, err
+	}
+	req.Header = hdr.Clone()
+	// net/http sends req.Host and ignores a Host entry in the header map,
+	// so a -H "Host: ..." for virtual hosts has to be moved there.
+	if host := req.Header.Get("Host"); host != "" {
+		req.Host = host
+		req.Header.Del("Host")
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return hit{}, err
+	}
+	defer resp.Body.Close()
+	size, _ := io.Copy(io.Discard, resp.Body)
+	return hit{url: url, status: resp.StatusCode, size: size, location: resp.Header.Get("Location")}, nil
+}
+
+// brute requests base+path for every path from a pool of workers and calls
+// report with each response as it arrives. Requests that fail outright are
+// written to stderr and skipped.
+func brute(client *http.Client, base string, hdr http.Header, words io.Reader, exts []string, workers int, report func(hit)) error {
+	base = strings.TrimSuffix(base, "/") + "/"
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < max(workers, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range jobs {
+				h, err := fetch(client, base+path, hdr)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "dirb: %v\n", err)
+					continue
+				}
+				report(h)
+			}
+		}()
+	}
+	err := paths(words, exts, func(path string) { jobs <- path })
+	close(jobs)
+	wg.Wait()
+	return err
+}
+
+func main() {
+	hdr := headers{}
+	flag.Var(hdr, "H", "extra request header as \"Name: value\" (repeatable)")
+	ext := flag.String("ext", "", "comma-separated extensions to also try for each word, e.g. php,bak")
+	follow := flag.Bool("follow", false, "follow redirects instead of reporting them")
+	workers := flag.Int("workers", 10, "concurrent requests")
+	timeout := flag.Duration("timeout", 10*time.Second, "per-request timeout")
+	hide := flag.String("hide", "404", "comma-separated status codes not to report")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: dirb [flags] base-url < wordlist")
+		os.Exit(2)
+	}
+	var exts []string
+	if *ext != "" {
+		exts = strings.Split(*ext, ",")
+	}
+	hidden := make(map[int]bool)
+	for _, c := range strings.Split(*hide, ",") {
+		if code, err := strconv.Atoi(strings.TrimSpace(c)); err == nil {
+			hidden[code] = true
+		}
+	}
+
+	client := &http.Client{Timeout: *timeout}
+	if !*follow {
+		client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	}
+	var mu sync.Mutex
+	err := brute(client, flag.Arg(0), http.Header(hdr), os.Stdin, exts, *workers, func(h hit) {
+		if hidden[h.status] {
+			return
+		}
+		line := fmt.Sprintf("%d %s (%d bytes)", h.status, h.url, h.size)
+		if h.location != "" {
+			line += " -> " + h.location
+		}
+		if interesting[h.status] {
+			line = "\x1b[1;32m" + line + "\x1b[0m"
+		}
+		mu.Lock()
+		fmt.Println(line)
+		mu.Unlock()
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "dirb: reading wordlist: %v\n", err)
+		os.Exit(1)
+	}
+}
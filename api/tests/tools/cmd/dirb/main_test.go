@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestBruteReportsStatuses(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/admin", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/login", http.StatusFound)
+	})
+	mux.HandleFunc("/secret", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+	mux.HandleFunc("/backup.bak", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("flag{d1rb}"))
+	})
+	mux.HandleFunc("/panel", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/panel/", http.StatusMovedPermanently)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client := srv.Client()
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	words := strings.NewReader("admin\n# comment\n\n/secret\nbackup\npanel\n")
+
+	var (
+		mu  sync.Mutex
+		got []string
+	)
+	err := brute(client, srv.URL+"/", nil, words, []string{".bak"}, 3, func(h hit) {
+		mu.Lock()
+		defer mu.Unlock()
+		line := strings.TrimPrefix(h.url, srv.URL) + " " + http.StatusText(h.status)
+		if h.location != "" {
+			line += " -> " + h.location
+		}
+		if h.status == http.StatusOK {
+			line += fmt.Sprintf(" (%d bytes)", h.size)
+		}
+		got = append(got, line)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(got)
+	want := []string{
+		"/admin Found -> /login",
+		"/admin.bak Not Found",
+		"/backup Not Found",
+		"/backup.bak OK (10 bytes)",
+		"/panel Moved Permanently -> /panel/",
+		"/panel.bak Not Found",
+		"/secret Forbidden",
+		"/secret.bak Not Found",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("hits:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestFetchSendsHeaders(t *testing.T) {
+	var host, cookie string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, cookie = r.Host, r.Header.Get("Cookie")
+	}))
+	defer srv.Close()
+
+	hdr := headers{}
+	for _, h := range []string{"Host: internal.ctf.local", "Cookie: session=admin"} {
+		if err := hdr.Set(h); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := fetch(srv.Client(), srv.URL+"/", http.Header(hdr)); err != nil {
+		t.Fatal(err)
+	}
+	if host != "internal.ctf.local" || cookie != "session=admin" {
+		t.Fatalf("server saw Host %q, Cookie %q", host, cookie)
+	}
+	if http.Header(hdr).Get("Host") == "" {
+		t.Fatal("fetch modified the shared headers")
+	}
+}
+
+func TestHeadersRejectsMalformed(t *testing.T) {
+	for _, s := range []string{"no colon", ": empty name"} {
+		if err := (headers{}).Set(s); err == nil {
+			t.Errorf("Set(%q) succeeded", s)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+func TestEntropyZeros(t *testing.T) {
+	if h := entropy(make([]byte, 4096)); h != 0 {
+		t.Fatalf("entropy of zeros = %v, want 0", h)
+	}
+	if h := entropy(nil); h != 0 {
+		t.Fatalf("entropy of nothing = %v, want 0", h)
+	}
+}
+
+func TestEntropyRandom(t *testing.T) {
+	data := make([]byte, 1<<16)
+	rand.New(rand.NewSource(1)).Read(data)
+	if h := entropy(data); h < 7.99 || h > 8 {
+		t.Fatalf("entropy of random bytes = %v, want close to 8", h)
+	}
+}
+
+func TestEntropyExact(t *testing.T) {
+	for _, tc := range []struct {
+		data []byte
+		want float64
+	}{
+		{[]byte("abab"), 1},
+		{[]byte("abcd"), 2},
+		{bytes.Repeat([]byte{0, 1, 2, 3, 4, 5, 6, 7}, 32), 3},
+	} {
+		if h := entropy(tc.data); h != tc.want {
+			t.Errorf("entropy(%q) = %v, want %v", tc.data, h, tc.want)
+		}
+	}
+}
+
+func TestVerdictAndSpark(t *testing.T) {
+	for _, tc := range []struct {
+		h       float64
+		verdict string
+		spark   byte
+	}{
+		{0, "padding or sparse data", ' '},
+		{4.2, "text or structured data", '+'},
+		{6, "code or mixed data", '#'},
+		{7.9, "encrypted or compressed", '@'},
+		{8, "encrypted or compressed", '@'},
+	} {
+		if got := verdict(tc.h); got != tc.verdict {
+			t.Errorf("verdict(%v) = %q, want %q", tc.h, got, tc.verdict)
+		}
+		if got := spark(tc.h); got != tc.spark {
+			t.Errorf("spark(%v) = %q, want %q", tc.h, got, tc.spark)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+// Filetype names the type of a file from its magic bytes and, with -carve,
+// lists every file signature embedded in it.
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/dnakov/ctf-arena/api/tests/tools/extract"
+	"github.com/dnakov/ctf-arena/api/tests/tools/flagfmt"
+)
+
+// magic is a file signature: sig must appear offset bytes into the file.
+type magic struct {
+	name   string
+	offset int
+	sig    []byte
+}
+
+// magics is checked in order, so longer or more specific signatures come
+// before shorter ones they could be mistaken for.
+var magics = []magic{
+	{"png", 0, []byte("\x89PNG\r\n\x1a\n")},
+	{"jpeg", 0, []byte("\xff\xd8\xff")},
+	{"gif", 0, []byte("GIF87a")},
+	{"gif", 0, []byte("GIF89a")},
+	{"bmp", 0, []byte("BM")},
+	{"webp", 8, []byte("WEBP")},
+	{"wav", 8, []byte("WAVE")},
+	{"zip", 0, []byte("PK\x03\x04")},
+	{"pdf", 0, []byte("%PDF-")},
+	{"elf", 0, []byte("\x7fELF")},
+	{"pe", 0, []byte("MZ")},
+	{"java-class", 0, []byte("\xca\xfe\xba\xbe")},
+	{"gzip", 0, []byte("\x1f\x8b\x08")},
+	{"bzip2", 0, []byte("BZh")},
+	{"xz", 0, []byte("\xfd7zXZ\x00")},
+	{"7z", 0, []byte("7z\xbc\xaf\x27\x1c")},
+	{"rar", 0, []byte("Rar!\x1a\x07")},
+	{"tar", 257, []byte("ustar")},
+	{"pcap", 0, []byte("\xd4\xc3\xb2\xa1")},
+	{"pcap", 0, []byte("\xa1\xb2\xc3\xd4")},
+	{"pcap", 0, []byte("\x4d\x3c\xb2\xa1")},
+	{"pcap", 0, []byte("\xa1\xb2\x3c\x4d")},
+	{"pcapng", 0, []byte("\x0a\x0d\x0d\x0a")},
+	{"sqlite", 0, []byte("SQLite format 3\x00")},
+	{"ogg", 0, []byte("OggS")},
+	{"mp3", 0, []byte("ID3")},
+	{"flac", 0, []byte("fLaC")},
+	{"psd", 0, []byte("8BPS")},
+}
+
+// zipContainers are formats that are plain ZIP archives underneath, keyed
+// by a member name that gives them away.
+var zipContainers = []struct {
+	member string
+	name   string
+}{
+	{"AndroidManifest.xml", "apk"},
+	{"META-INF/MANIFEST.MF", "jar"},
+	{"word/", "docx"},
+	{"xl/", "xlsx"},
+	{"ppt/", "pptx"},
+	{"mimetype", "odf/epub"},
+}
+
+// detect names the type of data from its leading magic bytes, or returns
+// "unknown".
+func detect(data []byte) string {
+	for _, m := range magics {
+		if len(data) < m.offset+len(m.sig) || !bytes.Equal(data[m.offset:m.offset+len(m.sig)], m.sig) {
+			continue
+		}
+		if m.name == "pe" && !isPE(data) {
+			return "dos-mz"
+		}
+		return m.name
+	}
+	return "unknown"
+}
+
+// isPE reports whether an MZ header points at a "PE\0\0" signature, which
+// separates Windows executables from bare DOS programs.
+func isPE(data []byte) bool {
+	if len(data) < 0x40 {
+		return false
+	}
+	off := int(binary.LittleEndian.Uint32(data[0x3c:]))
+	return off >= 0 && off+4 <= len(data) && bytes.Equal(data[off:off+4], []byte("PE\x00\x00"))
+}
+
+// zipContainer guesses which ZIP-based format data is, or returns "".
+func zipContainer(data []byte) string {
+	for _, c := range zipContainers {
+		if bytes.Contains(data, []byte(c.member)) {
+			return c.name
+		}
+	}
+	return ""
+}
+
+// minCarveSig skips two-byte signatures such as "MZ" and "BM" when carving;
+// they match all over arbitrary binary data.
+const minCarveSig = 3
+
+// hit is a signature found somewhere inside a buffer.
+type hit struct {
+	Offset int
+	Type   string
+}
+
+// carve finds every known signature anywhere in data, not just at the
+// start, so files appended to or embedded in another show up. Hits are
+// ordered by offset.
+func carve(data []byte) []hit {
+	var hits []hit
+	for _, m := range magics {
+		if len(m.sig) < minCarveSig {
+			continue
+		}
+		for pos := 0; pos < len(data); {
+			i := bytes.Index(data[pos:], m.sig)
+			if i < 0 {
+				break
+			}
+			if start := pos + i - m.offset; start >= 0 {
+				hits = append(hits, hit{Offset: start, Type: m.name})
+			}
+			pos += i + 1
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Offset < hits[j].Offset })
+	return hits
+}
+
+// findFlags returns the distinct flags matching pattern in data, each at
+// the offset it first appears. It searches the printable runs of data in
+// both ASCII and UTF-16LE so flags stored as wide strings, as in Windows
+// binaries, turn up too.
+func findFlags(data []byte, pattern string) []extract.Run {
+	var flags []extract.Run
+	for _, utf16 := range []bool{false, true} {
+		width := 1
+		if utf16 {
+			width = 2
+		}
+		for _, run := range extract.Strings(data, 4, utf16) {
+			for _, f := range flagfmt.FindFlags([]byte(run.S), pattern) {
+				flags = append(flags, extract.Run{Offset: run.Offset + strings.Index(run.S, f)*width, S: f})
+			}
+		}
+	}
+	sort.SliceStable(flags, func(i, j int) bool { return flags[i].Offset < flags[j].Offset })
+	seen := make(map[string]bool)
+	distinct := flags[:0]
+	for _, f := range flags {
+		if !seen[f.S] {
+			seen[f.S] = true
+			distinct = append(distinct, f)
+		}
+	}
+	return distinct
+}
+
+// line is one offset-tagged line of -carve or -flags output.
+type line struct {
+	offset int
+	text   string
+}
+
+// flagLines formats the flags in data as output lines, highlighting them
+// when color is set.
+func flagLines(data []byte, pattern string, color bool) []line {
+	var lines []line
+	for _, f := range findFlags(data, pattern) {
+		s := []byte(f.S)
+		if color {
+			s = flagfmt.Highlight(s, pattern)
+		}
+		lines = append(lines, line{f.Offset, "flag " + string(s)})
+	}
+	return lines
+}
+
+// isTerminal reports whether f is a terminal rather than a pipe or file,
+// so escape codes are only written where they will be rendered.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+func printLines(lines []line) {
+	for _, l := range lines {
+		fmt.Printf("0x%08x %s\n", l.offset, l.text)
+	}
+}
+
+func main() {
+	carveMode := flag.Bool("carve", false, "list every embedded signature with its offset")
+	flags := flag.Bool("flags", false, "also list flag{...} strings found in the data")
+	pattern := flag.String("pattern", flagfmt.DefaultPattern, "flag regexp for -flags, or a bare prefix such as HTB{")
+	flag.Parse()
+
+	in := io.Reader(os.Stdin)
+	if flag.NArg() > 0 {
+		f, err := os.Open(flag.Arg(0))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "filetype: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		in = f
+	}
+	data, err := io.ReadAll(in)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "filetype: %v\n", err)
+		os.Exit(1)
+	}
+	var found []line
+	if *flags {
+		found = flagLines(data, *pattern, isTerminal(os.Stdout))
+	}
+	if *carveMode {
+		// Flags are listed among the signatures, in offset order.
+		var lines []line
+		for _, h := range carve(data) {
+			lines = append(lines, line{h.Offset, h.Type})
+		}
+		lines = append(lines, found...)
+		sort.SliceStable(lines, func(i, j int) bool { return lines[i].offset < lines[j].offset })
+		printLines(lines)
+		return
+	}
+	defer printLines(found)
+	kind := detect(data)
+	if kind != "zip" {
+		fmt.Println(kind)
+		return
+	}
+	if c := zipContainer(data); c != "" {
+		fmt.Printf("zip (looks like %s)\n", c)
+		return
+	}
+	fmt.Println("zip (may also be a docx/xlsx/jar/apk container)")
+}
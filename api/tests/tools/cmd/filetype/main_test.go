@@ -0,0 +1,112 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dnakov/ctf-arena/api/tests/tools/extract"
+	"github.com/dnakov/ctf-arena/api/tests/tools/flagfmt"
+)
+
+// peStub is a minimal MZ header whose e_lfanew points at a PE signature.
+func peStub() []byte {
+	b := make([]byte, 0x48)
+	copy(b, "MZ")
+	b[0x3c] = 0x40
+	copy(b[0x40:], "PE\x00\x00")
+	return b
+}
+
+func TestDetect(t *testing.T) {
+	tar := make([]byte, 512)
+	copy(tar[257:], "ustar\x0000")
+	for _, tc := range []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"png", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), "png"},
+		{"jpeg", []byte("\xff\xd8\xff\xe0\x00\x10JFIF"), "jpeg"},
+		{"gif", []byte("GIF89a\x01\x00"), "gif"},
+		{"zip", []byte("PK\x03\x04\x14\x00"), "zip"},
+		{"pdf", []byte("%PDF-1.7\n"), "pdf"},
+		{"elf", []byte("\x7fELF\x02\x01\x01"), "elf"},
+		{"pe", peStub(), "pe"},
+		{"dos", []byte("MZ\x90\x00"), "dos-mz"},
+		{"gzip", []byte("\x1f\x8b\x08\x00"), "gzip"},
+		{"bzip2", []byte("BZh91AY&SY"), "bzip2"},
+		{"pcap", []byte("\xd4\xc3\xb2\xa1\x02\x00\x04\x00"), "pcap"},
+		{"tar", tar, "tar"},
+		{"text", []byte("just some text"), "unknown"},
+		{"empty", nil, "unknown"},
+	} {
+		if got := detect(tc.data); got != tc.want {
+			t.Errorf("detect(%s) = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestZipContainer(t *testing.T) {
+	docx := append([]byte("PK\x03\x04\x14\x00\x00\x00"), "word/document.xml"...)
+	if got := zipContainer(docx); got != "docx" {
+		t.Errorf("zipContainer(docx) = %q, want docx", got)
+	}
+	if got := zipContainer([]byte("PK\x03\x04flag.txt")); got != "" {
+		t.Errorf("zipContainer(plain zip) = %q, want \"\"", got)
+	}
+	if detect(docx) != "zip" {
+		t.Errorf("detect(docx) = %q, want zip", detect(docx))
+	}
+}
+
+func TestCarvePNGThenZIP(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01IEND\xaeB`\x82")
+	zip := []byte("PK\x03\x04\x14\x00\x00\x00\x00\x00flag.txtflag{hidden}")
+	data := append(append([]byte{}, png...), zip...)
+
+	hits := carve(data)
+	want := []hit{{0, "png"}, {len(png), "zip"}}
+	if len(hits) != len(want) {
+		t.Fatalf("carve = %v, want %v", hits, want)
+	}
+	for i := range want {
+		if hits[i] != want[i] {
+			t.Errorf("hit %d = %v, want %v", i, hits[i], want[i])
+		}
+	}
+}
+
+func TestFindFlagsASCIIAndUTF16(t *testing.T) {
+	var data []byte
+	data = append(data, "\x7fELF\x02\x01\x01\x00"...)
+	data = append(data, "junk flag{n4rrow} junk\x00"...)
+	wide := len(data) + 1
+	data = append(data, 0xff)
+	for _, c := range []byte("HTB{w1de}") {
+		data = append(data, c, 0)
+	}
+	data = append(data, 0xff, 0xfe)
+	// A repeat is listed once, at its first offset.
+	data = append(data, "\x00flag{n4rrow}\x00"...)
+
+	got := findFlags(data, flagfmt.DefaultPattern)
+	want := []extract.Run{{Offset: 13, S: "flag{n4rrow}"}, {Offset: wide, S: "HTB{w1de}"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("findFlags = %+v, want %+v", got, want)
+	}
+	got = findFlags(data, "HTB{")
+	if want := want[1:]; !reflect.DeepEqual(got, want) {
+		t.Fatalf("findFlags with prefix HTB{ = %+v, want %+v", got, want)
+	}
+}
+
+func TestFlagLinesHighlight(t *testing.T) {
+	data := []byte("\x00\x00flag{c0l0r}\x00")
+	if got, want := flagLines(data, flagfmt.DefaultPattern, false), []line{{2, "flag flag{c0l0r}"}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("flagLines = %+v, want %+v", got, want)
+	}
+	got := flagLines(data, flagfmt.DefaultPattern, true)
+	if want := "flag \x1b[1;31mflag{c0l0r}\x1b[0m"; len(got) != 1 || got[0].text != want {
+		t.Fatalf("flagLines with color = %+v, want %q", got, want)
+	}
+}
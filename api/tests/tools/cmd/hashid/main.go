@@ -0,0 +1,31 @@
+// Hashid reads one hash per line from stdin and lists the algorithms each
+// could have come from, most likely first.
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dnakov/ctf-arena/api/tests/tools/hashid"
+)
+
+func main() {
+	sc := bufio.NewScanner(os.Stdin)
+	for sc.Scan() {
+		h := strings.TrimSpace(sc.Text())
+		if h == "" {
+			continue
+		}
+		if c := hashid.Identify(h); c != nil {
+			fmt.Printf("%s: %s\n", h, strings.Join(c, ", "))
+		} else {
+			fmt.Printf("%s: unknown\n", h)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "hashid: reading stdin: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,71 @@
+// Hex decodes hex digits from stdin, ignoring whitespace and 0x or \x
+// prefixes, or encodes stdin with -e.
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// clean drops whitespace and 0x/\x prefixes from s, returning the remaining
+// hex digits alongside each digit's offset in the original input.
+func clean(s []byte) ([]byte, []int) {
+	digits := make([]byte, 0, len(s))
+	offsets := make([]int, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			continue
+		case (c == '0' || c == '\\') && i+1 < len(s) && (s[i+1] == 'x' || s[i+1] == 'X'):
+			i++
+			continue
+		}
+		digits = append(digits, c)
+		offsets = append(offsets, i)
+	}
+	return digits, offsets
+}
+
+func isHexDigit(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+// decode parses hexdump-style input such as "48 65 6c", "0x48656c" or
+// "\x48\x65\x6c". Errors name the offending byte's offset in the input.
+func decode(s []byte) ([]byte, error) {
+	digits, offsets := clean(s)
+	for i, c := range digits {
+		if !isHexDigit(c) {
+			return nil, fmt.Errorf("invalid hex character %q at byte %d", c, offsets[i])
+		}
+	}
+	if len(digits)%2 != 0 {
+		return nil, fmt.Errorf("odd number of hex digits; dangling digit at byte %d", offsets[len(offsets)-1])
+	}
+	return hex.DecodeString(string(digits))
+}
+
+func main() {
+	encode := flag.Bool("e", false, "encode stdin instead of decoding it")
+	flag.Parse()
+
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "hex: reading stdin: %v\n", err)
+		os.Exit(1)
+	}
+	if *encode {
+		fmt.Print(hex.EncodeToString(data))
+		return
+	}
+	decoded, err := decode(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "hex: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Print(string(decoded))
+}
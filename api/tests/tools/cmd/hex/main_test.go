@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestDecode(t *testing.T) {
+	for _, in := range []string{
+		"48656c6c6f",
+		"48 65 6c 6c 6f",
+		"48 65\n6c\t6c 6f\n",
+		"0x48656C6C6F",
+		"0x48 0x65 0x6c 0x6c 0x6f",
+		`\x48\x65\x6c\x6c\x6f`,
+	} {
+		got, err := decode([]byte(in))
+		if err != nil || string(got) != "Hello" {
+			t.Errorf("decode(%q) = %q, %v; want \"Hello\"", in, got, err)
+		}
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	for _, tc := range []struct{ in, want string }{
+		{"48 65 6", "odd number of hex digits; dangling digit at byte 6"},
+		{"48 6g", `invalid hex character 'g' at byte 4`},
+	} {
+		_, err := decode([]byte(tc.in))
+		if err == nil || err.Error() != tc.want {
+			t.Errorf("decode(%q) error = %v, want %q", tc.in, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// Inflate decompresses gzip, zlib or raw DEFLATE data from stdin, whichever
+// it turns out to be.
+package main
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"os"
+)
+
+// formats is tried in order. Raw DEFLATE goes last because it has no header
+// to reject the wrong input early, and compress/zlib refuses headerless
+// streams, so it needs its own attempt.
+var formats = []struct {
+	name string
+	open func(io.Reader) (io.Reader, error)
+}{
+	{"gzip", func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) }},
+	{"zlib", func(r io.Reader) (io.Reader, error) { return zlib.NewReader(r) }},
+	{"deflate", func(r io.Reader) (io.Reader, error) { return flate.NewReader(r), nil }},
+}
+
+// inflate decompresses data with the first format that reads it cleanly.
+// If every format fails partway, as with a truncated stream, it returns the
+// longest partial output along with that format's error.
+func inflate(data []byte) (out []byte, format string, err error) {
+	for _, f := range formats {
+		r, openErr := f.open(bytes.NewReader(data))
+		if openErr != nil {
+			if err == nil {
+				err = fmt.Errorf("%s: %w", f.name, openErr)
+			}
+			continue
+		}
+		var buf bytes.Buffer
+		_, readErr := io.Copy(&buf, r)
+		if readErr == nil {
+			return buf.Bytes(), f.name, nil
+		}
+		if buf.Len() > len(out) || out == nil {
+			out, format, err = buf.Bytes(), f.name, fmt.Errorf("%s: %w", f.name, readErr)
+		}
+	}
+	return out, format, err
+}
+
+func main() {
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "inflate: reading stdin: %v\n", err)
+		os.Exit(1)
+	}
+	out, format, err := inflate(data)
+	os.Stdout.Write(out)
+	if err != nil && len(out) == 0 {
+		fmt.Fprintf(os.Stderr, "inflate: input is not gzip, zlib or deflate (%v)\n", err)
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "inflate: warning: stream is truncated or corrupt, output is partial (%v)\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "inflate: %s\n", format)
+}
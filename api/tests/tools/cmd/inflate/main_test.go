@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"testing"
+)
+
+func compress(t *testing.T, format string, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch format {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "zlib":
+		w = zlib.NewWriter(&buf)
+	case "deflate":
+		fw, err := flate.NewWriter(&buf, flate.BestCompression)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w = fw
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestInflateDetectsFormat(t *testing.T) {
+	plain := bytes.Repeat([]byte("flag{squeezed} "), 20)
+	for _, format := range []string{"gzip", "zlib", "deflate"} {
+		out, got, err := inflate(compress(t, format, plain))
+		if err != nil {
+			t.Errorf("%s: %v", format, err)
+			continue
+		}
+		if got != format {
+			t.Errorf("%s stream detected as %s", format, got)
+		}
+		if !bytes.Equal(out, plain) {
+			t.Errorf("%s: inflated %q", format, out)
+		}
+	}
+}
+
+func TestInflateTruncated(t *testing.T) {
+	plain := bytes.Repeat([]byte("0123456789abcdef"), 256)
+	stream := compress(t, "zlib", plain)
+	out, format, err := inflate(stream[:len(stream)-8])
+	if err == nil {
+		t.Fatal("truncated stream inflated without error")
+	}
+	if format == "" || len(out) == 0 || !bytes.HasPrefix(plain, out) {
+		t.Fatalf("partial output = %d bytes as %q, want a prefix of the input", len(out), format)
+	}
+}
+
+func TestInflateNotCompressed(t *testing.T) {
+	if out, _, err := inflate([]byte("plain text")); err == nil && len(out) > 0 {
+		t.Fatalf("inflate(plain text) = %q with no error", out)
+	}
+}
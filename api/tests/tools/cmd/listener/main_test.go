@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// chunks feeds the given strings to bridge as local input, then EOF.
+func chunks(s ...string) <-chan []byte {
+	ch := make(chan []byte, len(s))
+	for _, c := range s {
+		ch <- []byte(c)
+	}
+	close(ch)
+	return ch
+}
+
+func TestServeBridgesRawBytes(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	localIn, localW := io.Pipe()
+	outR, outW := io.Pipe()
+	done := make(chan error, 1)
+	go func() { done <- serve(ln, readChunks(localIn), outW, false) }()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	// Remote to local: binary bytes come out of the pipe untouched.
+	sent := []byte("id\x00\xff\r\n\x1b[0m")
+	if _, err := conn.Write(sent); err != nil {
+		t.Fatal(err)
+	}
+	got := make([]byte, len(sent))
+	if _, err := io.ReadFull(outR, got); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, sent) {
+		t.Fatalf("local side got %q, want %q", got, sent)
+	}
+
+	// Local to remote, then the remote hanging up ends the session.
+	if _, err := localW.Write([]byte("uid=0(root)\n")); err != nil {
+		t.Fatal(err)
+	}
+	reply := make([]byte, len("uid=0(root)\n"))
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		t.Fatal(err)
+	}
+	if string(reply) != "uid=0(root)\n" {
+		t.Fatalf("remote side got %q", reply)
+	}
+	conn.Close()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("serve: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve did not return after the remote closed")
+	}
+	localW.Close()
+}
+
+func TestBridgeLocalEOFHalfCloses(t *testing.T) {
+	client, server := tcpPair(t)
+	var out bytes.Buffer
+	done := make(chan bool, 1)
+	go func() {
+		localEOF, _ := bridge(client, chunks("last words"), &out)
+		done <- localEOF
+	}()
+	// The peer sees our EOF but can still answer before closing.
+	got, err := io.ReadAll(server)
+	if err != nil || string(got) != "last words" {
+		t.Fatalf("peer read %q, %v", got, err)
+	}
+	server.Write([]byte("bye"))
+	server.Close()
+	if !<-done {
+		t.Error("bridge did not report local EOF")
+	}
+	if out.String() != "bye" {
+		t.Errorf("reply after local EOF = %q, want \"bye\"", out.String())
+	}
+}
+
+// tcpPair returns both ends of a loopback TCP connection.
+func tcpPair(t *testing.T) (client, server net.Conn) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	client, err = net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err = ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close(); server.Close() })
+	return client, server
+}
+
+func TestClientRoundTripTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		io.Copy(conn, conn)
+	}()
+
+	conn, err := dialOut("tcp", ln.Addr().String(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if _, err := bridge(conn, chunks("hello ", "echo\n"), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "hello echo\n" {
+		t.Fatalf("client got %q, want the message echoed", out.String())
+	}
+}
+
+func TestClientRoundTripUDP(t *testing.T) {
+	defer func(d time.Duration) { quietTimeout = d }(quietTimeout)
+	quietTimeout = 200 * time.Millisecond
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, addr, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			// Answer a little late, as a real service might.
+			time.Sleep(50 * time.Millisecond)
+			pc.WriteTo(buf[:n], addr)
+		}
+	}()
+
+	conn, err := dialOut("udp", pc.LocalAddr().String(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	start := time.Now()
+	if _, err := bridge(conn, chunks("hi\n"), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "hi\n" {
+		t.Fatalf("UDP client got %q after stdin EOF, want the echo", out.String())
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("bridge took %v to notice the peer went quiet", elapsed)
+	}
+}
+
+func TestDialOutFailure(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	if conn, err := dialOut("tcp", addr, 0); err == nil {
+		conn.Close()
+		t.Fatal("dialOut to a closed port succeeded")
+	}
+}
+
+func TestDialOutBindsSourcePort(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	free, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sport := free.Addr().(*net.TCPAddr).Port
+	free.Close()
+
+	conn, err := dialOut("tcp", ln.Addr().String(), sport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if got := conn.LocalAddr().(*net.TCPAddr).Port; got != sport {
+		t.Fatalf("local port = %d, want %d", got, sport)
+	}
+}
@@ -0,0 +1,52 @@
+// Morse decodes Morse code, or with -mode binary groups of binary digits,
+// from stdin.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dnakov/ctf-arena/api/tests/tools/decode"
+)
+
+var errMode = errors.New("unknown mode")
+
+// run decodes in as mode and writes the result, newline-terminated, to out.
+// For input that only partly decodes, what did decode is still written
+// before the error is returned.
+func run(mode string, in io.Reader, out io.Writer) error {
+	var decoder func(string) (string, error)
+	switch mode {
+	case "morse":
+		decoder = decode.Morse
+	case "binary":
+		decoder = decode.BinaryASCII
+	default:
+		return fmt.Errorf("%w %q", errMode, mode)
+	}
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return fmt.Errorf("reading stdin: %w", err)
+	}
+	s, err := decoder(string(data))
+	if _, werr := fmt.Fprintln(out, s); err == nil {
+		err = werr
+	}
+	return err
+}
+
+func main() {
+	mode := flag.String("mode", "morse", "input format: morse or binary")
+	flag.Parse()
+
+	if err := run(*mode, os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "morse: %v\n", err)
+		if errors.Is(err, errMode) {
+			os.Exit(2)
+		}
+		os.Exit(1)
+	}
+}
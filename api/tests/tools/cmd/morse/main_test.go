@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/*.golden from the current output")
+
+// TestRunGolden decodes each testdata/<name>.<mode> file in that mode and
+// compares the output with <name>.<mode>.golden.
+func TestRunGolden(t *testing.T) {
+	inputs, err := filepath.Glob("testdata/*.morse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	binary, _ := filepath.Glob("testdata/*.binary")
+	for _, path := range append(inputs, binary...) {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			in, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got bytes.Buffer
+			err = run(strings.TrimPrefix(filepath.Ext(path), "."), bytes.NewReader(in), &got)
+			if wantErr := strings.HasPrefix(filepath.Base(path), "unknown"); (err != nil) != wantErr {
+				t.Fatalf("run error = %v, want error %v", err, wantErr)
+			}
+			golden := path + ".golden"
+			if *update {
+				if err := os.WriteFile(golden, got.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got.Bytes(), want) {
+				t.Fatalf("output differs from %s:\n got %q\nwant %q", golden, got.Bytes(), want)
+			}
+		})
+	}
+}
+
+func TestRunUnknownMode(t *testing.T) {
+	var out bytes.Buffer
+	if err := run("semaphore", strings.NewReader("..."), &out); !errors.Is(err, errMode) {
+		t.Fatalf("run(semaphore) error = %v, want errMode", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("run(semaphore) wrote %q", out.String())
+	}
+}
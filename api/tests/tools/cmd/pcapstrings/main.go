@@ -0,0 +1,160 @@
+// Pcapstrings prints the printable strings in each packet of a libpcap or
+// pcapng capture, flagging the ones that look like credentials.
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+
+	"github.com/dnakov/ctf-arena/api/tests/tools/extract"
+)
+
+// credPattern flags HTTP Basic auth headers and login form or query
+// parameters.
+var credPattern = regexp.MustCompile(`(?i)authorization: *basic +([A-Za-z0-9+/=]+)|\b(?:user(?:name)?|login|email|pass(?:word|wd)?|pwd)=[^&\s]*`)
+
+// packets calls fn with the captured bytes of every packet in a libpcap or
+// pcapng file. Link-layer headers are left in place; strings don't care.
+func packets(data []byte, fn func(n int, pkt []byte)) error {
+	if len(data) < 4 {
+		return errors.New("file too short for a capture header")
+	}
+	switch m := binary.LittleEndian.Uint32(data); m {
+	case 0xa1b2c3d4, 0xa1b23c4d:
+		return pcapPackets(data, binary.LittleEndian, fn)
+	case 0xd4c3b2a1, 0x4d3cb2a1:
+		return pcapPackets(data, binary.BigEndian, fn)
+	case 0x0a0d0d0a:
+		return pcapngPackets(data, fn)
+	default:
+		return fmt.Errorf("not a pcap or pcapng file (magic %08x)", m)
+	}
+}
+
+// pcapPackets walks the 16-byte record headers that follow a libpcap
+// file's 24-byte global header.
+func pcapPackets(data []byte, order binary.ByteOrder, fn func(int, []byte)) error {
+	if len(data) < 24 {
+		return errors.New("truncated pcap global header")
+	}
+	n := 0
+	for off := 24; off < len(data); n++ {
+		if off+16 > len(data) {
+			return fmt.Errorf("truncated record header at byte %d", off)
+		}
+		incl := int(order.Uint32(data[off+8:]))
+		off += 16
+		if incl > len(data)-off {
+			return fmt.Errorf("packet %d runs past the end of the file", n+1)
+		}
+		fn(n+1, data[off:off+incl])
+		off += incl
+	}
+	return nil
+}
+
+// pcapngPackets walks pcapng blocks, taking the byte order from each
+// section header and the packet data from enhanced, simple and obsolete
+// packet blocks.
+func pcapngPackets(data []byte, fn func(int, []byte)) error {
+	var order binary.ByteOrder = binary.LittleEndian
+	n := 0
+	for off := 0; off < len(data); {
+		if off+12 > len(data) {
+			return fmt.Errorf("truncated block header at byte %d", off)
+		}
+		if binary.LittleEndian.Uint32(data[off:]) == 0x0a0d0d0a {
+			switch binary.LittleEndian.Uint32(data[off+8:]) {
+			case 0x1a2b3c4d:
+				order = binary.LittleEndian
+			case 0x4d3c2b1a:
+				order = binary.BigEndian
+			default:
+				return fmt.Errorf("bad byte-order magic in section header at byte %d", off)
+			}
+		}
+		typ, size := order.Uint32(data[off:]), int(order.Uint32(data[off+4:]))
+		if size < 12 || size > len(data)-off {
+			return fmt.Errorf("bad block length %d at byte %d", size, off)
+		}
+		body := data[off+8 : off+size-4]
+		var pkt []byte
+		switch {
+		case typ == 6 && len(body) >= 20: // enhanced packet
+			pkt = body[20:min(20+int(order.Uint32(body[12:])), len(body))]
+		case typ == 2 && len(body) >= 20: // obsolete packet
+			pkt = body[20:min(20+int(order.Uint32(body[12:])), len(body))]
+		case typ == 3 && len(body) >= 4: // simple packet, padded to 32 bits
+			pkt = body[4:min(4+int(order.Uint32(body)), len(body))]
+		}
+		if pkt != nil {
+			n++
+			fn(n, pkt)
+		}
+		off += size
+	}
+	return nil
+}
+
+// credential reports whether s looks like it carries a credential, adding
+// the decoded user:password for HTTP Basic auth.
+func credential(s string) (string, bool) {
+	m := credPattern.FindStringSubmatch(s)
+	if m == nil {
+		return "", false
+	}
+	if m[1] != "" {
+		if dec, err := base64.StdEncoding.DecodeString(m[1]); err == nil {
+			return fmt.Sprintf(" (%q)", dec), true
+		}
+	}
+	return "", true
+}
+
+func main() {
+	minLen := flag.Int("n", 6, "minimum string length")
+	credsOnly := flag.Bool("creds", false, "print only lines that look like credentials")
+	flag.Parse()
+
+	var in io.Reader = os.Stdin
+	if flag.NArg() > 0 {
+		f, err := os.Open(flag.Arg(0))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "pcapstrings: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		in = f
+	}
+	data, err := io.ReadAll(in)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "pcapstrings: reading input: %v\n", err)
+		os.Exit(1)
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+	err = packets(data, func(n int, pkt []byte) {
+		for _, run := range extract.Strings(pkt, max(*minLen, 1), false) {
+			extra, cred := credential(run.S)
+			switch {
+			case cred:
+				fmt.Fprintf(w, "%d: [cred] %s%s\n", n, run.S, extra)
+			case !*credsOnly:
+				fmt.Fprintf(w, "%d: %s\n", n, run.S)
+			}
+		}
+	})
+	if err != nil {
+		w.Flush()
+		fmt.Fprintf(os.Stderr, "pcapstrings: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const httpProbe = "GET / HTTP/1.0\r\n\r\n"
+
+// bannerSize is the most of a banner that is read and kept.
+const bannerSize = 1024
+
+// tlsClientHello is a minimal TLS 1.2 ClientHello offering common ECDHE
+// and RSA suites. Any TLS server answers it with a ServerHello or at least
+// an alert, either of which identifies the port as TLS.
+var tlsClientHello = []byte{
+	0x16, 0x03, 0x01, 0x00, 0x59, // record: handshake, 89 bytes
+	0x01, 0x00, 0x00, 0x55, // ClientHello, 85 bytes
+	0x03, 0x03, // TLS 1.2
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, // random
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0x00,       // no session id
+	0x00, 0x10, // cipher suites
+	0xc0, 0x2f, 0xc0, 0x30, 0xc0, 0x2b, 0xc0, 0x2c,
+	0x00, 0x9c, 0x00, 0x9d, 0x00, 0x2f, 0x00, 0x35,
+	0x01, 0x00, // null compression
+	0x00, 0x1c, // extensions
+	0x00, 0x0a, 0x00, 0x06, 0x00, 0x04, 0x00, 0x1d, 0x00, 0x17, // groups: x25519, P-256
+	0x00, 0x0b, 0x00, 0x02, 0x01, 0x00, // point formats: uncompressed
+	0x00, 0x0d, 0x00, 0x08, 0x00, 0x06, 0x04, 0x03, 0x08, 0x04, 0x04, 0x01, // signature algorithms
+}
+
+// probes maps well-known ports to the opener their service expects before
+// it says anything. Ports not listed are read passively first.
+var probes = map[int][]byte{
+	80:   []byte(httpProbe),
+	443:  tlsClientHello,
+	6379: []byte("PING\r\n"),
+	8000: []byte(httpProbe),
+	8080: []byte(httpProbe),
+	8443: tlsClientHello,
+	8888: []byte(httpProbe),
+}
+
+// probeService reads the banner of the service on conn, sending the opener
+// from probes for its port first. Unlisted ports fall back to readBanner.
+// Either way no read outlasts timeout.
+func probeService(conn net.Conn, port int, timeout time.Duration) []byte {
+	payload, ok := probes[port]
+	if !ok {
+		return readBanner(conn, timeout)
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+	if _, err := conn.Write(payload); err != nil {
+		return nil
+	}
+	buf := make([]byte, bannerSize)
+	n, _ := conn.Read(buf)
+	return buf[:n]
+}
+
+// readBanner returns up to bannerSize bytes sent by the peer. Services
+// that wait for the client to speak first (HTTP) get a minimal request once
+// the initial read times out.
+func readBanner(conn net.Conn, timeout time.Duration) []byte {
+	buf := make([]byte, bannerSize)
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	n, err := conn.Read(buf)
+	var ne net.Error
+	if n == 0 && errors.As(err, &ne) && ne.Timeout() {
+		if _, err := conn.Write([]byte(httpProbe)); err != nil {
+			return nil
+		}
+		conn.SetReadDeadline(time.Now().Add(timeout))
+		n, _ = io.ReadFull(conn, buf)
+	}
+	return buf[:n]
+}
+
+// escapeBanner makes b safe to print on a terminal, hex-escaping anything
+// outside printable ASCII.
+func escapeBanner(b []byte) string {
+	b = bytes.TrimRight(b, " \t\r\n\x00")
+	var sb strings.Builder
+	for _, c := range b {
+		switch {
+		case c == '\r':
+			sb.WriteString(`\r`)
+		case c == '\n':
+			sb.WriteString(`\n`)
+		case c == '\\':
+			sb.WriteString(`\\`)
+		case c >= 0x20 && c < 0x7f:
+			sb.WriteByte(c)
+		default:
+			fmt.Fprintf(&sb, `\x%02x`, c)
+		}
+	}
+	return sb.String()
+}
+
+// grabBanner reads whatever the service on conn announces and returns it in
+// printable form.
+func grabBanner(conn net.Conn, timeout time.Duration) string {
+	return escapeBanner(readBanner(conn, timeout))
+}
+
+// recordConn is a net.Conn that keeps the first bannerSize bytes read
+// through it, so a TLS handshake with a port that turns out not to speak
+// TLS still leaves its banner behind.
+type recordConn struct {
+	net.Conn
+	read []byte
+}
+
+func (c *recordConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if room := bannerSize - len(c.read); room > 0 {
+		c.read = append(c.read, p[:min(n, room)]...)
+	}
+	return n, err
+}
+
+// leafCert completes a TLS handshake over conn without verifying the
+// chain, so self-signed and expired certificates still come back, and
+// returns the server's leaf certificate. host is sent as the SNI name
+// unless it is an IP address.
+func leafCert(ctx context.Context, conn net.Conn, host string) (*x509.Certificate, error) {
+	tc := tls.Client(conn, &tls.Config{ServerName: host, InsecureSkipVerify: true})
+	if err := tc.HandshakeContext(ctx); err != nil {
+		return nil, err
+	}
+	certs := tc.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return nil, errors.New("server sent no certificate")
+	}
+	return certs[0], nil
+}
+
+// grabCert returns the leaf certificate of the TLS service on host:port.
+// timeout bounds the dial and handshake together.
+func grabCert(host string, port int, timeout time.Duration) (*x509.Certificate, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	conn, err := new(net.Dialer).DialContext(ctx, "tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return leafCert(ctx, conn, host)
+}
+
+// handshake tries TLS over a freshly opened conn, giving up after timeout,
+// and returns the leaf certificate, or nil when the port doesn't speak
+// TLS, along with the raw bytes the port sent.
+func handshake(ctx context.Context, conn net.Conn, host string, timeout time.Duration) (*x509.Certificate, []byte) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	rc := &recordConn{Conn: conn}
+	c, _ := leafCert(ctx, rc, host)
+	return c, rc.read
+}
+
+// signature recognizes a product from its banner. If re has a capture
+// group, its match is appended to product as the version.
+type signature struct {
+	re      *regexp.Regexp
+	product string
+}
+
+// signatures is consulted in order by fingerprint, so specific products go
+// before generic protocol matches. Append entries for custom CTF services.
+var signatures = []signature{
+	{regexp.MustCompile(`^SSH-[\d.]+-OpenSSH[_-]([\w.]+)`), "OpenSSH"},
+	{regexp.MustCompile(`^SSH-[\d.]+-dropbear[_-]?([\w.]*)`), "Dropbear sshd"},
+	{regexp.MustCompile(`^220[ -].*vsFTPd ([\d.]+)`), "vsftpd"},
+	{regexp.MustCompile(`^220[ -].*ProFTPD ([\d.]+)`), "ProFTPD"},
+	{regexp.MustCompile(`^220[ -].*Postfix`), "Postfix smtpd"},
+	{regexp.MustCompile(`^220[ -].*Exim ([\d.]+)`), "Exim smtpd"},
+	{regexp.MustCompile(`^\* OK.*Dovecot`), "Dovecot imapd"},
+	{regexp.MustCompile(`^-NOAUTH|^-DENIED Redis|^\+PONG|^-ERR.*redis`), "Redis"},
+	{regexp.MustCompile(`mysql_native_password|caching_sha2_password`), "MySQL"},
+	{regexp.MustCompile(`(?i)Server: Apache(?:/([\d.]+))?`), "Apache httpd"},
+	{regexp.MustCompile(`(?i)Server: nginx(?:/([\d.]+))?`), "nginx"},
+	{regexp.MustCompile(`(?i)Server: Microsoft-IIS/([\d.]+)`), "Microsoft IIS"},
+	{regexp.MustCompile(`(?i)Server: gunicorn(?:/([\d.]+))?`), "gunicorn"},
+	{regexp.MustCompile(`(?i)Server: Werkzeug/([\d.]+)`), "Werkzeug httpd"},
+	{regexp.MustCompile(`^[\x15\x16]\x03[\x00-\x04]`), "TLS"},
+	{regexp.MustCompile(`^SSH-`), "SSH"},
+	{regexp.MustCompile(`^220[ -]`), "FTP/SMTP"},
+	{regexp.MustCompile(`^HTTP/\d(?:\.\d)? \d{3}`), "HTTP"},
+}
+
+// fingerprint guesses the product behind banner, or returns "" when no
+// signature matches. Multi-line responses such as HTTP headers are joined
+// into one line first so a signature can match any header.
+func fingerprint(banner string) string {
+	lines := strings.Split(banner, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, "\r")
+	}
+	joined := strings.Join(lines, " ")
+	for _, sig := range signatures {
+		m := sig.re.FindStringSubmatch(joined)
+		if m == nil {
+			continue
+		}
+		if len(m) > 1 && m[1] != "" {
+			return sig.product + " " + m[1]
+		}
+		return sig.product
+	}
+	return ""
+}
+
+// parsePayload reads a -send argument: hex digits, optionally prefixed
+// with 0x and spaced out, or text with \n, \r, \t, \0, \\ and \xNN
+// escapes. Text that happens to be valid hex, such as "cafe", is read as
+// hex.
+func parsePayload(s string) ([]byte, error) {
+	h := strings.ReplaceAll(strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X"), " ", "")
+	if b, err := hex.DecodeString(h); err == nil && h != "" {
+		return b, nil
+	}
+	var out []byte
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			out = append(out, s[i])
+			continue
+		}
+		if i+1 == len(s) {
+			return nil, errors.New("trailing backslash")
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			out = append(out, '\n')
+		case 'r':
+			out = append(out, '\r')
+		case 't':
+			out = append(out, '\t')
+		case '0':
+			out = append(out, 0)
+		case '\\':
+			out = append(out, '\\')
+		case 'x':
+			if i+2 >= len(s) {
+				return nil, fmt.Errorf("short \\x escape at byte %d", i-1)
+			}
+			b, err := hex.DecodeString(s[i+1 : i+3])
+			if err != nil {
+				return nil, fmt.Errorf("bad \\x escape at byte %d", i-1)
+			}
+			out = append(out, b[0])
+			i += 2
+		default:
+			return nil, fmt.Errorf("unknown escape \\%c at byte %d", s[i], i-1)
+		}
+	}
+	return out, nil
+}
+
+// exchange sends opts.send over conn if set and reads the reply until
+// opts.expect matches it, the peer stops sending, 4 KiB have arrived or
+// opts.timeout passes. It reports whether the reply matched.
+func exchange(conn net.Conn, opts scanOptions) ([]byte, bool) {
+	conn.SetDeadline(time.Now().Add(opts.timeout))
+	if len(opts.send) > 0 {
+		if _, err := conn.Write(opts.send); err != nil {
+			return nil, false
+		}
+	}
+	buf := make([]byte, 0, 4096)
+	for len(buf) < cap(buf) {
+		n, err := conn.Read(buf[len(buf):cap(buf)])
+		buf = buf[:len(buf)+n]
+		if opts.expect != nil && opts.expect.Match(buf) {
+			return buf, true
+		}
+		if err != nil {
+			break
+		}
+	}
+	return buf, false
+}
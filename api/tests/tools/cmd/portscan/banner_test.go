@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFingerprint(t *testing.T) {
+	for _, tc := range []struct{ banner, want string }{
+		{"SSH-2.0-OpenSSH_8.9p1 Ubuntu-3ubuntu0.6\r\n", "OpenSSH 8.9p1"},
+		{"SSH-2.0-dropbear_2022.83\r\n", "Dropbear sshd 2022.83"},
+		{"SSH-2.0-Go\r\n", "SSH"},
+		{"220 (vsFTPd 3.0.3)\r\n", "vsftpd 3.0.3"},
+		{"220 mail.ctf.local ESMTP Postfix (Ubuntu)\r\n", "Postfix smtpd"},
+		{"HTTP/1.1 200 OK\r\nDate: Mon, 01 Jan 2024 00:00:00 GMT\r\nServer: nginx/1.18.0\r\n\r\n", "nginx 1.18.0"},
+		{"HTTP/1.0 404 Not Found\r\nContent-Type: text/html\r\nServer: Werkzeug/2.2.2 Python/3.10.12\r\n", "Werkzeug httpd 2.2.2"},
+		{"HTTP/1.1 403 Forbidden\r\nServer: Apache\r\n", "Apache httpd"},
+		{"HTTP/1.1 200 OK\r\nContent-Length: 0\r\n", "HTTP"},
+		{"+PONG\r\n", "Redis"},
+		{"\x16\x03\x03\x00\x5d\x02", "TLS"},
+		{"welcome to the flag vault\n", ""},
+		{"", ""},
+	} {
+		if got := fingerprint(tc.banner); got != tc.want {
+			t.Errorf("fingerprint(%q) = %q, want %q", tc.banner, got, tc.want)
+		}
+	}
+}
+
+// withProbe registers opener as the probe for port for the rest of the
+// test, the way a custom CTF service would be added to probes.
+func withProbe(t *testing.T, port int, opener []byte) {
+	t.Helper()
+	old, had := probes[port]
+	probes[port] = opener
+	t.Cleanup(func() {
+		if had {
+			probes[port] = old
+		} else {
+			delete(probes, port)
+		}
+	})
+}
+
+// redisLike answers PING with +PONG and anything else with an error, and
+// says nothing until spoken to.
+func redisLike(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				sc := bufio.NewScanner(conn)
+				for sc.Scan() {
+					if strings.TrimSpace(sc.Text()) == "PING" {
+						conn.Write([]byte("+PONG\r\n"))
+					} else {
+						conn.Write([]byte("-ERR unknown command\r\n"))
+					}
+				}
+			}()
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func probeBanner(t *testing.T, port int, timeout time.Duration) string {
+	t.Helper()
+	conn, err := net.Dial("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	return string(probeService(conn, port, timeout))
+}
+
+func TestProbeServiceHTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "Werkzeug/3.0.1 Python/3.12.0")
+	}))
+	defer srv.Close()
+	port := srv.Listener.Addr().(*net.TCPAddr).Port
+	withProbe(t, port, []byte(httpProbe))
+
+	start := time.Now()
+	banner := probeBanner(t, port, 2*time.Second)
+	if !strings.HasPrefix(banner, "HTTP/1.0 200 OK\r\n") {
+		t.Fatalf("banner = %q, want an HTTP response", banner)
+	}
+	if got := fingerprint(banner); got != "Werkzeug httpd 3.0.1" {
+		t.Errorf("fingerprint = %q", got)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("listed port waited %v before speaking", elapsed)
+	}
+}
+
+func TestProbeServiceRedisPing(t *testing.T) {
+	port := redisLike(t)
+	withProbe(t, port, probes[6379])
+
+	banner := probeBanner(t, port, 2*time.Second)
+	if banner != "+PONG\r\n" {
+		t.Fatalf("banner = %q, want +PONG", banner)
+	}
+	if got := fingerprint(banner); got != "Redis" {
+		t.Errorf("fingerprint = %q, want Redis", got)
+	}
+}
+
+func TestProbeServiceUnlistedFallsBackToHTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer srv.Close()
+	port := srv.Listener.Addr().(*net.TCPAddr).Port
+	if _, ok := probes[port]; ok {
+		t.Skipf("port %d has a probe", port)
+	}
+	if banner := probeBanner(t, port, 100*time.Millisecond); !strings.HasPrefix(banner, "HTTP/1.0 200 OK") {
+		t.Fatalf("banner = %q, want an HTTP response after the passive read", banner)
+	}
+}
+
+// tokenServer replies with a token only to a client that says "HELLO ctf"
+// and with "nope" to anything else.
+func tokenServer(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				line, _ := bufio.NewReader(conn).ReadString('\n')
+				if line == "HELLO ctf\n" {
+					conn.Write([]byte("TOKEN 7f3a9c\n"))
+				} else {
+					conn.Write([]byte("nope\n"))
+				}
+			}()
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func TestSendExpect(t *testing.T) {
+	port := tokenServer(t)
+	for _, tc := range []struct {
+		send   string
+		sport  bool
+		state  portState
+		banner string
+	}{
+		{`HELLO ctf\n`, false, stateMatch, "TOKEN 7f3a9c\n"},
+		{`48454c4c4f206374660a`, false, stateMatch, "TOKEN 7f3a9c\n"},
+		{`HELLO ctf\n`, true, stateMatch, "TOKEN 7f3a9c\n"},
+		{`HELLO\n`, false, stateNoMatch, "nope\n"},
+	} {
+		payload, err := parsePayload(tc.send)
+		if err != nil {
+			t.Fatal(err)
+		}
+		opts := scanOptions{timeout: time.Second, send: payload, expect: regexp.MustCompile(`^TOKEN [0-9a-f]+`)}
+		if tc.sport {
+			opts.sport = closedPort(t)
+		}
+		var got []Result
+		for r := range streamTargets(context.Background(), hostList("127.0.0.1"), "tcp", []int{port}, 1, false, false, false, opts) {
+			got = append(got, r)
+		}
+		if len(got) != 1 || got[0].State != tc.state || got[0].Banner != tc.banner {
+			t.Errorf("send %s (sport %v): results %+v, want %s with banner %q", tc.send, tc.sport, got, tc.state, tc.banner)
+		}
+	}
+}
+
+func TestParsePayload(t *testing.T) {
+	for _, tc := range []struct{ in, want string }{
+		{"cafe", "\xca\xfe"},
+		{"0x de ad", "\xde\xad"},
+		{`PING\r\n`, "PING\r\n"},
+		{`a\tb\0c\\d\x41`, "a\tb\x00c\\dA"},
+		{"hello", "hello"},
+	} {
+		got, err := parsePayload(tc.in)
+		if err != nil || string(got) != tc.want {
+			t.Errorf("parsePayload(%q) = %q, %v; want %q", tc.in, got, err, tc.want)
+		}
+	}
+	for _, tc := range []struct{ in, want string }{
+		{`abc\`, "trailing backslash"},
+		{`ab\x4`, `short \x escape at byte 2`},
+		{`\xzz`, `bad \x escape at byte 0`},
+		{`a\q`, `unknown escape \q at byte 1`},
+	} {
+		if _, err := parsePayload(tc.in); err == nil || err.Error() != tc.want {
+			t.Errorf("parsePayload(%q) error = %v, want %q", tc.in, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// expiredCert makes a self-signed certificate that expired a year ago,
+// the kind a verifying client refuses.
+func expiredCert(t *testing.T) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "vault.ctf.local"},
+		DNSNames:     []string{"vault.ctf.local", "flag-is-in-the-san.ctf.local"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().AddDate(-2, 0, 0),
+		NotAfter:     time.Now().AddDate(-1, 0, 0),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// tlsServer starts an HTTPS server presenting cert, or httptest's own
+// certificate when cert is nil. Handshakes the scanner abandons once it has
+// the certificate are not logged.
+func tlsServer(t *testing.T, cert *tls.Certificate) (*httptest.Server, string, int) {
+	t.Helper()
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	if cert != nil {
+		srv.TLS = &tls.Config{Certificates: []tls.Certificate{*cert}}
+	}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	addr := srv.Listener.Addr().(*net.TCPAddr)
+	return srv, addr.IP.String(), addr.Port
+}
+
+func TestGrabCertHTTPTest(t *testing.T) {
+	srv, host, port := tlsServer(t, nil)
+	c, err := grabCert(host, port, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := srv.Certificate(); !c.Equal(want) {
+		t.Fatalf("got certificate for %v, want httptest's", c.Subject)
+	}
+	if !slices.Contains(c.DNSNames, "example.com") {
+		t.Errorf("SANs = %v, want example.com among them", c.DNSNames)
+	}
+}
+
+func TestGrabCertExpiredSelfSigned(t *testing.T) {
+	cert := expiredCert(t)
+	_, host, port := tlsServer(t, &cert)
+	c, err := grabCert(host, port, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Subject.CommonName != "vault.ctf.local" {
+		t.Errorf("CN = %q", c.Subject.CommonName)
+	}
+	info := newCertInfo(c)
+	if want := []string{"vault.ctf.local", "flag-is-in-the-san.ctf.local", "127.0.0.1"}; !reflect.DeepEqual(info.SANs, want) {
+		t.Errorf("SANs = %v, want %v", info.SANs, want)
+	}
+	s := info.String()
+	for _, want := range []string{"subject=CN=vault.ctf.local (self-signed)", "(expired)"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("%q does not contain %q", s, want)
+		}
+	}
+}
+
+func TestGrabCertTimesOutSilentServer(t *testing.T) {
+	// A port that accepts but never answers the ClientHello.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+	start := time.Now()
+	if _, err := grabCert("127.0.0.1", ln.Addr().(*net.TCPAddr).Port, 200*time.Millisecond); err == nil {
+		t.Fatal("handshake with a silent server succeeded")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("grabCert took %v with a 200ms timeout", elapsed)
+	}
+}
+
+func TestStreamCertAndBanner(t *testing.T) {
+	cert := expiredCert(t)
+	_, host, port := tlsServer(t, &cert)
+	plain := greeter(t, "SSH-2.0-OpenSSH_9.6\r\n", new(atomic.Int32))
+	got := map[int]Result{}
+	for r := range streamTargets(context.Background(), hostList(host), "tcp", []int{port, plain}, 2, true, true, false, testOpts) {
+		got[r.Port] = r
+	}
+	if c := got[port].Cert; c == nil || c.Subject != "CN=vault.ctf.local" {
+		t.Errorf("TLS port cert = %+v", c)
+	}
+	if p := got[port].Product; p != "TLS" {
+		t.Errorf("TLS port fingerprint = %q, want TLS", p)
+	}
+	// A port that isn't TLS keeps the banner the failed handshake read.
+	if r := got[plain]; r.Cert != nil || r.Product != "OpenSSH 9.6" {
+		t.Errorf("SSH port = %+v, want its banner and no cert", r)
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// rateLimiter caps how many connections are started per second across all
+// workers sharing it. A nil *rateLimiter never blocks.
+type rateLimiter struct {
+	ticker *time.Ticker
+}
+
+// newRateLimiter allows perSecond new connections a second, or returns nil
+// (unlimited) when perSecond is not positive.
+func newRateLimiter(perSecond int) *rateLimiter {
+	if perSecond <= 0 || perSecond > int(time.Second) {
+		return nil
+	}
+	return &rateLimiter{ticker: time.NewTicker(time.Second / time.Duration(perSecond))}
+}
+
+// wait blocks until the next connection may start or ctx is done.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	select {
+	case <-l.ticker.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *rateLimiter) stop() {
+	if l != nil {
+		l.ticker.Stop()
+	}
+}
+
+// scanOptions controls how each probe connects. A TCP port is retried up
+// to retries more times, with backoff between attempts, when the dial is
+// refused or times out. A non-zero sport binds every outbound connection
+// to that local source port, and fastClose resets TCP connections on close
+// (see setFastClose). Open TCP ports are sent send, when set, and their
+// reply is checked against expect, when set (see exchange).
+type scanOptions struct {
+	timeout   time.Duration
+	limiter   *rateLimiter
+	retries   int
+	backoff   time.Duration
+	sport     int
+	fastClose bool
+	send      []byte
+	expect    *regexp.Regexp
+}
+
+// reuseAddr sets SO_REUSEADDR so concurrent dials can share one bound
+// source port; their four-tuples still differ by destination.
+func reuseAddr(network, address string, c syscall.RawConn) error {
+	var serr error
+	err := c.Control(func(fd uintptr) {
+		serr = syscall.SetsockoptInt(int(fd), syscall.SOL_SOCKET, syscall.SO_REUSEADDR, 1)
+	})
+	if err != nil {
+		return err
+	}
+	return serr
+}
+
+// dialer builds the net.Dialer for network, bound to opts.sport if set.
+func dialer(network string, opts scanOptions) *net.Dialer {
+	d := &net.Dialer{Timeout: opts.timeout}
+	if opts.sport != 0 {
+		if strings.HasPrefix(network, "udp") {
+			d.LocalAddr = &net.UDPAddr{Port: opts.sport}
+		} else {
+			d.LocalAddr = &net.TCPAddr{Port: opts.sport}
+		}
+		d.Control = reuseAddr
+	}
+	return d
+}
+
+// checkSourcePort binds opts.sport once up front, so a port the user may
+// not bind fails the scan with a clear message instead of showing up as
+// every port being filtered.
+func checkSourcePort(opts scanOptions) error {
+	if opts.sport == 0 {
+		return nil
+	}
+	lc := net.ListenConfig{Control: reuseAddr}
+	ln, err := lc.Listen(context.Background(), "tcp", net.JoinHostPort("", strconv.Itoa(opts.sport)))
+	if errors.Is(err, syscall.EACCES) {
+		return fmt.Errorf("binding source port %d: permission denied (ports below 1024 need root or CAP_NET_BIND_SERVICE)", opts.sport)
+	}
+	if err != nil {
+		return fmt.Errorf("binding source port %d: %w", opts.sport, err)
+	}
+	return ln.Close()
+}
+
+// setFastClose sets a zero linger on TCP connections so Close sends an RST
+// instead of a FIN. The local end then skips TIME_WAIT, which otherwise
+// piles up and exhausts ephemeral ports over sweeps of thousands of ports.
+// It reports whether conn was a TCP connection.
+func setFastClose(conn net.Conn) bool {
+	tc, ok := conn.(*net.TCPConn)
+	if !ok {
+		return false
+	}
+	return tc.SetLinger(0) == nil
+}
+
+// dial waits for the rate limiter and connects to host:port.
+func dial(ctx context.Context, network, host string, port int, opts scanOptions) (net.Conn, error) {
+	if err := opts.limiter.wait(ctx); err != nil {
+		return nil, err
+	}
+	conn, err := dialer(network, opts).DialContext(ctx, network, net.JoinHostPort(host, strconv.Itoa(port)))
+	if err == nil && opts.fastClose {
+		setFastClose(conn)
+	}
+	return conn, err
+}
+
+// retryable reports whether a failed dial might succeed on another try:
+// congested networks drop SYNs and services can come up mid-scan.
+func retryable(err error) bool {
+	var ne net.Error
+	return errors.Is(err, syscall.ECONNREFUSED) || errors.As(err, &ne) && ne.Timeout()
+}
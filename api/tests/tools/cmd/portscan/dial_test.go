@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterPacesScan(t *testing.T) {
+	const n, rate = 10, 50
+	ports := make([]int, n)
+	for i := range ports {
+		ports[i] = closedPort(t)
+	}
+	opts := scanOptions{timeout: time.Second, limiter: newRateLimiter(rate)}
+	defer opts.limiter.stop()
+
+	start := time.Now()
+	scanPorts(context.Background(), "127.0.0.1", ports, 8, opts)
+	if elapsed, min := time.Since(start), n*time.Second/rate; elapsed < min {
+		t.Fatalf("%d dials at %d/s took %v, want at least %v", n, rate, elapsed, min)
+	}
+}
+
+func TestRateLimiterNil(t *testing.T) {
+	for _, rate := range []int{0, -1} {
+		if l := newRateLimiter(rate); l != nil {
+			t.Errorf("newRateLimiter(%d) = %v, want nil", rate, l)
+		}
+	}
+	var l *rateLimiter
+	if err := l.wait(context.Background()); err != nil {
+		t.Fatalf("nil limiter wait: %v", err)
+	}
+	l.stop()
+}
+
+func TestRateLimiterCancel(t *testing.T) {
+	l := newRateLimiter(1)
+	defer l.stop()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.wait(ctx); err == nil {
+		t.Fatal("wait on a cancelled context returned nil")
+	}
+}
+
+func TestDialBindsSourcePort(t *testing.T) {
+	sport := closedPort(t)
+	opts := scanOptions{timeout: time.Second, sport: sport}
+	if err := checkSourcePort(opts); err != nil {
+		t.Fatal(err)
+	}
+	conn, err := dial(context.Background(), "tcp", "127.0.0.1", listen(t), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if got := conn.LocalAddr().(*net.TCPAddr).Port; got != sport {
+		t.Fatalf("local port = %d, want %d", got, sport)
+	}
+}
+
+// greeter serves banner to each connection and holds it open until the
+// client closes, counting the connections it accepts in accepted.
+func greeter(t *testing.T, banner string, accepted *atomic.Int32) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			accepted.Add(1)
+			go func() {
+				defer conn.Close()
+				io.WriteString(conn, banner)
+				io.Copy(io.Discard, conn)
+			}()
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func TestSourcePortBannerUsesProbeConnection(t *testing.T) {
+	// Closing the probe's connection leaves its four-tuple in TIME_WAIT, so
+	// off loopback a second dial from the same source port fails. Check the
+	// banner came over the one connection the probe opened.
+	var accepted atomic.Int32
+	port := greeter(t, "SSH-2.0-OpenSSH_9.6\r\n", &accepted)
+	opts := scanOptions{timeout: time.Second, sport: closedPort(t)}
+	var got []Result
+	for r := range streamTargets(context.Background(), hostList("127.0.0.1"), "tcp", []int{port}, 1, true, false, false, opts) {
+		got = append(got, r)
+	}
+	if len(got) != 1 || got[0].Product != "OpenSSH 9.6" {
+		t.Fatalf("results = %+v, want the OpenSSH banner", got)
+	}
+	if n := accepted.Load(); n != 1 {
+		t.Fatalf("scan opened %d connections, want 1", n)
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// pingPorts are tried by the TCP fallback of pingSweep.
+var pingPorts = []int{80, 443}
+
+// pingWorkers bounds the concurrent connects of the TCP ping.
+const pingWorkers = 64
+
+// discoverBatch is how many hosts -discover pings at a time, so a sweep of
+// a large network never holds every address at once.
+const discoverBatch = 256
+
+// icmpChecksum is the Internet checksum of an ICMP message.
+func icmpChecksum(b []byte) uint16 {
+	var sum uint32
+	for i := 0; i+1 < len(b); i += 2 {
+		sum += uint32(b[i])<<8 | uint32(b[i+1])
+	}
+	if len(b)%2 == 1 {
+		sum += uint32(b[len(b)-1]) << 8
+	}
+	for sum > 0xffff {
+		sum = sum>>16 + sum&0xffff
+	}
+	return ^uint16(sum)
+}
+
+// pinger finds which hosts are up: IPv4 hosts by ICMP echo over one raw
+// socket when it can be opened, and everything else by TCP connect. Every
+// echo request and connect waits for opts.limiter, so discovery keeps to
+// -rate like the scan itself. up and total count the hosts pinged so far.
+type pinger struct {
+	opts      scanOptions
+	icmp      net.PacketConn
+	seq       int
+	up, total int
+}
+
+// newPinger opens the raw ICMP socket, which needs root or CAP_NET_RAW,
+// and logs to stderr which method discovery will use.
+func newPinger(opts scanOptions) *pinger {
+	p := &pinger{opts: opts}
+	conn, err := net.ListenPacket("ip4:icmp", "0.0.0.0")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "portscan: ICMP unavailable (%v); discovering hosts by TCP connect to ports %v\n", err, pingPorts)
+		return p
+	}
+	fmt.Fprintln(os.Stderr, "portscan: discovering hosts by ICMP echo")
+	p.icmp = conn
+	return p
+}
+
+func (p *pinger) close() {
+	if p.icmp != nil {
+		p.icmp.Close()
+	}
+}
+
+// pingICMP sends one echo request to each IPv4 host and collects the
+// replies that arrive within opts.timeout of the last request.
+func (p *pinger) pingICMP(ctx context.Context, hosts []string) map[string]bool {
+	id := uint16(os.Getpid())
+	alive := make(map[string]bool)
+	p.icmp.SetReadDeadline(time.Time{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		buf := make([]byte, 1500)
+		for {
+			n, addr, err := p.icmp.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			// Echo reply with our identifier.
+			if n >= 8 && buf[0] == 0 && uint16(buf[4])<<8|uint16(buf[5]) == id {
+				alive[addr.(*net.IPAddr).IP.String()] = true
+			}
+		}
+	}()
+
+	for _, host := range hosts {
+		if p.opts.limiter.wait(ctx) != nil {
+			break
+		}
+		seq := p.seq
+		p.seq++
+		msg := []byte{8, 0, 0, 0, byte(id >> 8), byte(id), byte(seq >> 8), byte(seq), 'p', 'i', 'n', 'g'}
+		sum := icmpChecksum(msg)
+		msg[2], msg[3] = byte(sum>>8), byte(sum)
+		p.icmp.WriteTo(msg, &net.IPAddr{IP: net.ParseIP(host)})
+	}
+	p.icmp.SetReadDeadline(time.Now().Add(p.opts.timeout))
+	<-done
+	return alive
+}
+
+// pingTCP treats a host as up if any of pingPorts accepts or refuses a
+// connection within opts.timeout: a refusal still means something answered.
+func (p *pinger) pingTCP(ctx context.Context, hosts []string) map[string]bool {
+	var mu sync.Mutex
+	alive := make(map[string]bool)
+	forEachTarget(ctx, hostList(hosts...), pingPorts, pingWorkers, func(t target) {
+		mu.Lock()
+		up := alive[t.host]
+		mu.Unlock()
+		if up {
+			return
+		}
+		conn, err := dial(ctx, "tcp", t.host, t.port, p.opts)
+		if err == nil {
+			conn.Close()
+		}
+		if err == nil || errors.Is(err, syscall.ECONNREFUSED) {
+			mu.Lock()
+			alive[t.host] = true
+			mu.Unlock()
+		}
+	})
+	return alive
+}
+
+// sweep returns the hosts of batch that answer, in their original order.
+func (p *pinger) sweep(ctx context.Context, batch []string) []string {
+	var v4, rest []string
+	for _, h := range batch {
+		if ip := net.ParseIP(h); p.icmp != nil && ip != nil && ip.To4() != nil {
+			v4 = append(v4, h)
+		} else {
+			rest = append(rest, h)
+		}
+	}
+	alive := make(map[string]bool)
+	if len(v4) > 0 {
+		alive = p.pingICMP(ctx, v4)
+	}
+	for h := range p.pingTCP(ctx, rest) {
+		alive[h] = true
+	}
+	var up []string
+	for _, h := range batch {
+		if ip := net.ParseIP(h); alive[h] || ip != nil && alive[ip.String()] {
+			up = append(up, h)
+		}
+	}
+	p.up += len(up)
+	p.total += len(batch)
+	return up
+}
+
+// discover yields the hosts of feed that answer a ping, pinging
+// discoverBatch of them at a time as the scan consumes them.
+func (p *pinger) discover(ctx context.Context, feed hostFeed) hostFeed {
+	return func(yield func(string) bool) {
+		batch := make([]string, 0, discoverBatch)
+		flush := func() bool {
+			for _, h := range p.sweep(ctx, batch) {
+				if !yield(h) {
+					return false
+				}
+			}
+			batch = batch[:0]
+			return ctx.Err() == nil
+		}
+		more := true
+		feed(func(h string) bool {
+			batch = append(batch, h)
+			if len(batch) == discoverBatch {
+				more = flush()
+			}
+			return more
+		})
+		if more && len(batch) > 0 {
+			flush()
+		}
+	}
+}
+
+// pingSweep returns the hosts that answer within timeout, in their original
+// order. IPv4 hosts get an ICMP echo; when raw sockets aren't available, as
+// for an unprivileged user, every host gets a TCP connect to pingPorts
+// instead, as do IPv6 hosts and hostnames in any case. The method used is
+// logged to stderr.
+func pingSweep(hosts []string, timeout time.Duration) []string {
+	p := newPinger(scanOptions{timeout: timeout})
+	defer p.close()
+	return p.sweep(context.Background(), hosts)
+}
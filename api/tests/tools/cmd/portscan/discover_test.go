@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// withPingPorts points the TCP ping at ports for the rest of the test.
+func withPingPorts(t *testing.T, ports ...int) {
+	t.Helper()
+	old := pingPorts
+	pingPorts = ports
+	t.Cleanup(func() { pingPorts = old })
+}
+
+func TestPingTCPFallback(t *testing.T) {
+	withPingPorts(t, closedPort(t), listen(t))
+	// A pinger without an ICMP socket is what an unprivileged user gets.
+	p := &pinger{opts: testOpts}
+	got := p.sweep(context.Background(), []string{"host.invalid", "127.0.0.1", "localhost"})
+	if want := []string{"127.0.0.1", "localhost"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("sweep = %v, want %v", got, want)
+	}
+	if p.up != 2 || p.total != 3 {
+		t.Fatalf("counted %d of %d up, want 2 of 3", p.up, p.total)
+	}
+}
+
+func TestDiscoverStreamsBatches(t *testing.T) {
+	// Refused connects count as up, so every loopback address answers.
+	withPingPorts(t, closedPort(t))
+	const n = discoverBatch + 44
+	var want []string
+	for i := 1; i <= n; i++ {
+		want = append(want, fmt.Sprintf("127.0.%d.%d", i/256, i%256))
+	}
+	fed := 0
+	feed := func(yield func(string) bool) {
+		for _, h := range want {
+			fed++
+			if !yield(h) {
+				return
+			}
+		}
+	}
+
+	p := &pinger{opts: testOpts}
+	var got []string
+	p.discover(context.Background(), feed)(func(h string) bool {
+		if len(got) == 0 && fed > discoverBatch {
+			t.Errorf("first host came out after %d were read, want at most %d", fed, discoverBatch)
+		}
+		got = append(got, h)
+		return true
+	})
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("discover yielded %d hosts, want all %d in order", len(got), n)
+	}
+}
+
+func TestDiscoverKeepsToRate(t *testing.T) {
+	withPingPorts(t, closedPort(t))
+	const n, rate = 10, 50
+	var hosts []string
+	for i := 1; i <= n; i++ {
+		hosts = append(hosts, fmt.Sprintf("127.0.0.%d", i))
+	}
+	p := &pinger{opts: scanOptions{timeout: time.Second, limiter: newRateLimiter(rate)}}
+	defer p.opts.limiter.stop()
+
+	start := time.Now()
+	p.discover(context.Background(), hostList(hosts...))(func(string) bool { return true })
+	if elapsed, min := time.Since(start), n*time.Second/rate; elapsed < min {
+		t.Fatalf("pinging %d hosts at %d/s took %v, want at least %v", n, rate, elapsed, min)
+	}
+}
+
+func TestDiscoverStopsOnCancel(t *testing.T) {
+	withPingPorts(t, closedPort(t))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	p := &pinger{opts: testOpts}
+	p.discover(ctx, hostList("127.0.0.1", "127.0.0.2"))(func(h string) bool {
+		t.Errorf("cancelled discovery yielded %s", h)
+		return true
+	})
+}
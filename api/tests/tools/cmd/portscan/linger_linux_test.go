@@ -0,0 +1,65 @@
+//go:build !386
+
+package main
+
+import (
+	"context"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+// lingerOf reads SO_LINGER back from conn, which the syscall package can
+// set but not get.
+func lingerOf(t *testing.T, conn net.Conn) syscall.Linger {
+	t.Helper()
+	raw, err := conn.(*net.TCPConn).SyscallConn()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var l syscall.Linger
+	var errno syscall.Errno
+	err = raw.Control(func(fd uintptr) {
+		size := uint32(unsafe.Sizeof(l))
+		_, _, errno = syscall.Syscall6(syscall.SYS_GETSOCKOPT, fd, syscall.SOL_SOCKET, syscall.SO_LINGER,
+			uintptr(unsafe.Pointer(&l)), uintptr(unsafe.Pointer(&size)), 0)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errno != 0 {
+		t.Fatal(errno)
+	}
+	return l
+}
+
+func TestFastCloseSetsZeroLinger(t *testing.T) {
+	port := listen(t)
+	for _, fast := range []bool{false, true} {
+		conn, err := dial(context.Background(), "tcp", "127.0.0.1", port, scanOptions{timeout: time.Second, fastClose: fast})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := conn.(*net.TCPConn); !ok {
+			t.Fatalf("dial returned %T, want *net.TCPConn", conn)
+		}
+		l := lingerOf(t, conn)
+		conn.Close()
+		if on := l.Onoff != 0; on != fast || l.Linger != 0 {
+			t.Errorf("fastClose %v: SO_LINGER = %+v", fast, l)
+		}
+	}
+}
+
+func TestFastCloseSkipsUDP(t *testing.T) {
+	conn, err := net.Dial("udp", "127.0.0.1:9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if setFastClose(conn) {
+		t.Fatal("setFastClose reported a UDP connection as TCP")
+	}
+}
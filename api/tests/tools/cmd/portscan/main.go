@@ -0,0 +1,183 @@
+// Portscan probes TCP or UDP ports on a host or CIDR range and reports the
+// open ones, optionally with banners, fingerprints and TLS certificates, as
+// text, JSON or Nmap-style reports.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// exitInterrupted is the status of a scan stopped by a signal, after its
+// partial results have been printed.
+const exitInterrupted = 130
+
+// handleSignals cancels the scan on the first SIGINT or SIGTERM, so main
+// can still print what was found, and exits on the second in case that
+// hangs. The returned channel is closed once the first signal arrives.
+func handleSignals(cancel context.CancelFunc) <-chan struct{} {
+	sigs := make(chan os.Signal, 2)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	interrupted := make(chan struct{})
+	go func() {
+		<-sigs
+		fmt.Fprintln(os.Stderr, "portscan: interrupted, printing partial results (again to quit now)")
+		close(interrupted)
+		cancel()
+		<-sigs
+		os.Exit(exitInterrupted)
+	}()
+	return interrupted
+}
+
+func main() {
+	portList := flag.String("p", "22,80,443", "ports to scan, as a list and ranges such as 22,80,8000-8100 (- for all)")
+	proto := flag.String("proto", "tcp", "protocol to probe: tcp or udp")
+	banner := flag.Bool("banner", false, "grab and print banners from open TCP ports")
+	cert := flag.Bool("cert", false, "show the TLS certificate of open TCP ports that speak TLS (not with -send or -expect)")
+	service := flag.Bool("service", false, "print the conventional service name of open ports")
+	jsonOut := flag.Bool("json", false, "print results as a JSON array")
+	oG := flag.String("oG", "", "also write Nmap greppable output to this file (- for stdout)")
+	oX := flag.String("oX", "", "also write Nmap XML output to this file (- for stdout)")
+	timing := flag.Bool("timing", false, "show closed and filtered ports too, with dial latency")
+	deadline := flag.Duration("deadline", 0, "overall time limit for the scan (0 for none)")
+	retries := flag.Int("retries", 0, "extra attempts for ports that refuse or time out")
+	backoff := flag.Duration("backoff", 50*time.Millisecond, "pause between retries of a port")
+	discover := flag.Bool("discover", false, "ping hosts first and scan only those that answer")
+	workers := flag.Int("workers", 3, "concurrent connections shared across all hosts")
+	send := flag.String("send", "", "payload to send to open TCP ports, as hex or text with \\n-style escapes")
+	expect := flag.String("expect", "", "mark open TCP ports open+match or open+nomatch by whether the reply matches this regexp")
+	sport := flag.Int("sport", 0, "bind outbound connections to this local source port (0 for any)")
+	fastClose := flag.Bool("fastclose", false, "reset connections on close instead of a graceful FIN, avoiding TIME_WAIT")
+	rate := flag.Int("rate", 0, "maximum new connections per second across all workers (0 for unlimited)")
+	flag.Parse()
+
+	if *proto != "tcp" && *proto != "udp" {
+		fmt.Fprintf(os.Stderr, "portscan: unknown protocol %q\n", *proto)
+		os.Exit(2)
+	}
+	target := "127.0.0.1"
+	if flag.NArg() > 0 {
+		target = flag.Arg(0)
+	}
+	var (
+		host  string
+		ipnet *net.IPNet
+		err   error
+	)
+	if strings.Contains(target, "/") {
+		_, ipnet, err = net.ParseCIDR(target)
+	} else {
+		host, err = resolveTarget(target)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "portscan: %v\n", err)
+		os.Exit(1)
+	}
+	ports, err := parsePorts(*portList)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "portscan: -p: %v\n", err)
+		os.Exit(2)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupted := handleSignals(cancel)
+	if *deadline > 0 {
+		ctx, cancel = context.WithTimeout(ctx, *deadline)
+		defer cancel()
+	}
+
+	opts := scanOptions{
+		timeout:   time.Second,
+		limiter:   newRateLimiter(*rate),
+		retries:   *retries,
+		backoff:   *backoff,
+		sport:     *sport,
+		fastClose: *fastClose,
+	}
+	defer opts.limiter.stop()
+	if *send != "" {
+		if opts.send, err = parsePayload(*send); err != nil {
+			fmt.Fprintf(os.Stderr, "portscan: -send: %v\n", err)
+			os.Exit(2)
+		}
+	}
+	if *expect != "" {
+		if opts.expect, err = regexp.Compile(*expect); err != nil {
+			fmt.Fprintf(os.Stderr, "portscan: -expect: %v\n", err)
+			os.Exit(2)
+		}
+	}
+	if err := checkSourcePort(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "portscan: %v\n", err)
+		os.Exit(1)
+	}
+
+	hosts := hostList(host)
+	if ipnet != nil {
+		hosts = func(yield func(string) bool) { walkCIDR(ipnet, yield) }
+	}
+	var pings *pinger
+	if *discover {
+		pings = newPinger(opts)
+		defer pings.close()
+		hosts = pings.discover(ctx, hosts)
+	}
+	start := time.Now()
+	stream := streamTargets(ctx, hosts, *proto, ports, *workers, *banner, *cert, *timing, opts)
+
+	// Text goes out as results arrive unless stdout carries another format.
+	live := !*jsonOut && *oG != "-" && *oX != "-"
+	results := []Result{}
+	for r := range stream {
+		if live {
+			if ipnet != nil {
+				fmt.Print(r.Host, " ")
+			}
+			fmt.Println(r.text(*service, *timing))
+		}
+		results = append(results, r)
+	}
+	sortResults(results)
+	if pings != nil {
+		fmt.Fprintf(os.Stderr, "portscan: %d of %d hosts up\n", pings.up, pings.total)
+	}
+
+	if *jsonOut {
+		out, err := json.Marshal(results)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "portscan: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(out))
+	}
+	report := Report{Args: os.Args, Start: start, End: time.Now(), Results: results}
+	for _, out := range []struct {
+		path  string
+		write func(io.Writer) error
+	}{{*oG, report.WriteGreppable}, {*oX, report.WriteXML}} {
+		if out.path == "" {
+			continue
+		}
+		if err := writeReport(out.path, out.write); err != nil {
+			fmt.Fprintf(os.Stderr, "portscan: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	select {
+	case <-interrupted:
+		os.Exit(exitInterrupted)
+	default:
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// Report is a finished scan in a form other tools can ingest: WriteXML and
+// WriteGreppable follow Nmap's -oX and -oG layouts closely enough for
+// parsers of those formats to accept them.
+type Report struct {
+	Args       []string
+	Start, End time.Time
+	Results    []Result // sorted by host, then port
+}
+
+// hosts returns the distinct hosts of r.Results in order, each with its
+// results.
+func (r Report) hosts() ([]string, map[string][]Result) {
+	var order []string
+	byHost := make(map[string][]Result)
+	for _, res := range r.Results {
+		if _, ok := byHost[res.Host]; !ok {
+			order = append(order, res.Host)
+		}
+		byHost[res.Host] = append(byHost[res.Host], res)
+	}
+	return order, byHost
+}
+
+// WriteGreppable writes the report as Nmap's greppable output: one
+// "Host: ... Ports: ..." line per host, each port as
+// port/state/protocol/owner/service/rpc/version/.
+func (r Report) WriteGreppable(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# portscan scan initiated %s as: %s\n", r.Start.Format(time.ANSIC), strings.Join(r.Args, " "))
+	order, byHost := r.hosts()
+	for _, h := range order {
+		fmt.Fprintf(bw, "Host: %s ()\tStatus: Up\n", h)
+		ports := make([]string, len(byHost[h]))
+		for i, res := range byHost[h] {
+			ports[i] = fmt.Sprintf("%d/%s/%s//%s//%s/", res.Port, res.State, res.Proto, res.Service, strings.ReplaceAll(res.Product, "/", "|"))
+		}
+		fmt.Fprintf(bw, "Host: %s ()\tPorts: %s\n", h, strings.Join(ports, ", "))
+	}
+	addrs, up := "addresses", "hosts"
+	if len(order) == 1 {
+		addrs, up = "address", "host"
+	}
+	fmt.Fprintf(bw, "# portscan done at %s -- %d IP %s (%d %s up) scanned in %.2f seconds\n",
+		r.End.Format(time.ANSIC), len(order), addrs, len(order), up, r.End.Sub(r.Start).Seconds())
+	return bw.Flush()
+}
+
+// The xml* types mirror the subset of Nmap's XML schema that parsers read.
+type xmlRun struct {
+	XMLName  xml.Name  `xml:"nmaprun"`
+	Scanner  string    `xml:"scanner,attr"`
+	Args     string    `xml:"args,attr"`
+	Start    int64     `xml:"start,attr"`
+	StartStr string    `xml:"startstr,attr"`
+	Version  string    `xml:"xmloutputversion,attr"`
+	Hosts    []xmlHost `xml:"host"`
+	Stats    xmlStats  `xml:"runstats"`
+}
+
+type xmlHost struct {
+	Status  xmlStatus  `xml:"status"`
+	Address xmlAddress `xml:"address"`
+	Ports   []xmlPort  `xml:"ports>port"`
+}
+
+type xmlStatus struct {
+	State  string `xml:"state,attr"`
+	Reason string `xml:"reason,attr"`
+}
+
+type xmlAddress struct {
+	Addr string `xml:"addr,attr"`
+	Type string `xml:"addrtype,attr"`
+}
+
+type xmlPort struct {
+	Protocol string      `xml:"protocol,attr"`
+	PortID   int         `xml:"portid,attr"`
+	State    xmlState    `xml:"state"`
+	Service  *xmlService `xml:"service,omitempty"`
+}
+
+type xmlState struct {
+	State string `xml:"state,attr"`
+}
+
+type xmlService struct {
+	Name    string `xml:"name,attr"`
+	Product string `xml:"product,attr,omitempty"`
+	Method  string `xml:"method,attr"`
+}
+
+type xmlStats struct {
+	Finished struct {
+		Time    int64   `xml:"time,attr"`
+		Elapsed float64 `xml:"elapsed,attr"`
+	} `xml:"finished"`
+	Hosts struct {
+		Up    int `xml:"up,attr"`
+		Total int `xml:"total,attr"`
+	} `xml:"hosts"`
+}
+
+// WriteXML writes the report as an Nmap-style XML document.
+func (r Report) WriteXML(w io.Writer) error {
+	run := xmlRun{
+		Scanner:  "portscan",
+		Args:     strings.Join(r.Args, " "),
+		Start:    r.Start.Unix(),
+		StartStr: r.Start.Format(time.ANSIC),
+		Version:  "1.05",
+	}
+	order, byHost := r.hosts()
+	for _, h := range order {
+		host := xmlHost{Status: xmlStatus{State: "up", Reason: "user-set"}, Address: xmlAddress{Addr: h, Type: "ipv4"}}
+		if ip := net.ParseIP(h); ip != nil && ip.To4() == nil {
+			host.Address.Type = "ipv6"
+		}
+		for _, res := range byHost[h] {
+			port := xmlPort{Protocol: res.Proto, PortID: res.Port, State: xmlState{string(res.State)}}
+			if res.Service != "" || res.Product != "" {
+				port.Service = &xmlService{Name: res.Service, Product: res.Product, Method: "table"}
+				if res.Product != "" {
+					port.Service.Method = "probed"
+				}
+			}
+			host.Ports = append(host.Ports, port)
+		}
+		run.Hosts = append(run.Hosts, host)
+	}
+	run.Stats.Finished.Time = r.End.Unix()
+	run.Stats.Finished.Elapsed = r.End.Sub(r.Start).Seconds()
+	run.Stats.Hosts.Up, run.Stats.Hosts.Total = len(order), len(order)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(run); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// writeReport runs write on a new file at path, or on stdout when path is
+// "-".
+func writeReport(path string, write func(io.Writer) error) error {
+	if path == "-" {
+		return write(os.Stdout)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testReport = Report{
+	Args:  []string{"portscan", "-oX", "-", "10.10.0.0/30"},
+	Start: time.Date(2024, 3, 9, 12, 0, 0, 0, time.UTC),
+	End:   time.Date(2024, 3, 9, 12, 0, 2, 500_000_000, time.UTC),
+	Results: []Result{
+		{Host: "10.10.0.1", Port: 22, Proto: "tcp", State: stateOpen, Service: "ssh", Product: "OpenSSH 9.6"},
+		{Host: "10.10.0.1", Port: 80, Proto: "tcp", State: stateOpen, Service: "http"},
+		{Host: "10.10.0.2", Port: 443, Proto: "tcp", State: stateOpen},
+		{Host: "fd00::2", Port: 53, Proto: "udp", State: stateOpenFiltered, Service: "dns"},
+	},
+}
+
+func TestWriteXMLUnmarshals(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testReport.WriteXML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	// Only the parts of the Nmap schema a parser would read.
+	var run struct {
+		XMLName xml.Name `xml:"nmaprun"`
+		Hosts   []struct {
+			Address struct {
+				Addr string `xml:"addr,attr"`
+				Type string `xml:"addrtype,attr"`
+			} `xml:"address"`
+			Ports []struct {
+				Protocol string `xml:"protocol,attr"`
+				PortID   int    `xml:"portid,attr"`
+				State    struct {
+					State string `xml:"state,attr"`
+				} `xml:"state"`
+			} `xml:"ports>port"`
+		} `xml:"host"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &run); err != nil {
+		t.Fatalf("%v in:\n%s", err, buf.Bytes())
+	}
+	var got []string
+	for _, h := range run.Hosts {
+		for _, p := range h.Ports {
+			got = append(got, strings.Join([]string{h.Address.Addr, h.Address.Type, p.Protocol, strconv.Itoa(p.PortID), p.State.State}, " "))
+		}
+	}
+	want := []string{
+		"10.10.0.1 ipv4 tcp 22 open",
+		"10.10.0.1 ipv4 tcp 80 open",
+		"10.10.0.2 ipv4 tcp 443 open",
+		"fd00::2 ipv6 udp 53 open|filtered",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ports:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if !strings.HasPrefix(buf.String(), xml.Header) {
+		t.Error("document lacks the XML header")
+	}
+}
+
+func TestWriteGreppable(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testReport.WriteGreppable(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "# portscan scan initiated Sat Mar  9 12:00:00 2024 as: portscan -oX - 10.10.0.0/30\n" +
+		"Host: 10.10.0.1 ()\tStatus: Up\n" +
+		"Host: 10.10.0.1 ()\tPorts: 22/open/tcp//ssh//OpenSSH 9.6/, 80/open/tcp//http///\n" +
+		"Host: 10.10.0.2 ()\tStatus: Up\n" +
+		"Host: 10.10.0.2 ()\tPorts: 443/open/tcp/////\n" +
+		"Host: fd00::2 ()\tStatus: Up\n" +
+		"Host: fd00::2 ()\tPorts: 53/open|filtered/udp//dns///\n" +
+		"# portscan done at Sat Mar  9 12:00:02 2024 -- 3 IP addresses (3 hosts up) scanned in 2.50 seconds\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("greppable output:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bufio"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// services maps "port/proto" to the name printed next to open ports. It
+// covers the usual CTF suspects; add entries for challenge-specific ports.
+var services = map[string]string{
+	"21/tcp":    "ftp",
+	"22/tcp":    "ssh",
+	"23/tcp":    "telnet",
+	"25/tcp":    "smtp",
+	"53/tcp":    "dns",
+	"53/udp":    "dns",
+	"69/udp":    "tftp",
+	"80/tcp":    "http",
+	"110/tcp":   "pop3",
+	"123/udp":   "ntp",
+	"143/tcp":   "imap",
+	"161/udp":   "snmp",
+	"443/tcp":   "https",
+	"445/tcp":   "smb",
+	"1433/tcp":  "mssql",
+	"3306/tcp":  "mysql",
+	"3389/tcp":  "rdp",
+	"5432/tcp":  "postgres",
+	"5900/tcp":  "vnc",
+	"6379/tcp":  "redis",
+	"8080/tcp":  "http-alt",
+	"8443/tcp":  "https-alt",
+	"9200/tcp":  "elasticsearch",
+	"27017/tcp": "mongodb",
+}
+
+var (
+	etcServicesOnce sync.Once
+	etcServices     map[string]string
+)
+
+// loadEtcServices parses /etc/services into the same "port/proto" keyed
+// form as services. A missing file leaves the map empty.
+func loadEtcServices() {
+	etcServices = map[string]string{}
+	f, err := os.Open("/etc/services")
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line, _, _ := strings.Cut(sc.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		if _, ok := etcServices[fields[1]]; !ok {
+			etcServices[fields[1]] = fields[0]
+		}
+	}
+}
+
+// serviceName returns the conventional service name for port/proto, or ""
+// if neither services nor /etc/services knows it.
+func serviceName(port int, proto string) string {
+	key := strconv.Itoa(port) + "/" + proto
+	if name, ok := services[key]; ok {
+		return name
+	}
+	etcServicesOnce.Do(loadEtcServices)
+	return etcServices[key]
+}
+
+// Result is the outcome of probing one port.
+type Result struct {
+	Host    string
+	Port    int
+	Proto   string
+	State   portState
+	Service string
+	Product string
+	Banner  string
+	Latency time.Duration
+	Cert    *certInfo
+}
+
+// certInfo is the part of a TLS leaf certificate worth showing: CTF
+// hostnames and hints tend to hide in the names.
+type certInfo struct {
+	Subject   string    `json:"subject"`
+	Issuer    string    `json:"issuer"`
+	SANs      []string  `json:"sans,omitempty"`
+	NotBefore time.Time `json:"not_before"`
+	NotAfter  time.Time `json:"not_after"`
+}
+
+func newCertInfo(c *x509.Certificate) *certInfo {
+	info := &certInfo{
+		Subject:   c.Subject.String(),
+		Issuer:    c.Issuer.String(),
+		NotBefore: c.NotBefore,
+		NotAfter:  c.NotAfter,
+	}
+	info.SANs = append(info.SANs, c.DNSNames...)
+	for _, ip := range c.IPAddresses {
+		info.SANs = append(info.SANs, ip.String())
+	}
+	info.SANs = append(info.SANs, c.EmailAddresses...)
+	for _, u := range c.URIs {
+		info.SANs = append(info.SANs, u.String())
+	}
+	return info
+}
+
+// String formats the certificate on one line, flagging self-signed and
+// expired certificates.
+func (c *certInfo) String() string {
+	s := "subject=" + c.Subject
+	if c.Issuer == c.Subject {
+		s += " (self-signed)"
+	} else {
+		s += " issuer=" + c.Issuer
+	}
+	if len(c.SANs) > 0 {
+		s += " san=" + strings.Join(c.SANs, ",")
+	}
+	s += fmt.Sprintf(" valid %s to %s", c.NotBefore.UTC().Format(time.DateOnly), c.NotAfter.UTC().Format(time.DateOnly))
+	if now := time.Now(); now.After(c.NotAfter) {
+		s += " (expired)"
+	} else if now.Before(c.NotBefore) {
+		s += " (not yet valid)"
+	}
+	return s
+}
+
+// resultJSON fixes the field order of the JSON encoding. Banners that are
+// not valid UTF-8 are base64-encoded and flagged via banner_encoding.
+type resultJSON struct {
+	Host           string    `json:"host"`
+	Port           int       `json:"port"`
+	Proto          string    `json:"proto"`
+	Open           bool      `json:"open"`
+	State          string    `json:"state"`
+	Service        string    `json:"service"`
+	Product        string    `json:"product,omitempty"`
+	Banner         string    `json:"banner"`
+	BannerEncoding string    `json:"banner_encoding,omitempty"`
+	LatencyMS      float64   `json:"latency_ms"`
+	Cert           *certInfo `json:"cert,omitempty"`
+}
+
+func (r Result) MarshalJSON() ([]byte, error) {
+	j := resultJSON{
+		Host:    r.Host,
+		Port:    r.Port,
+		Proto:   r.Proto,
+		Open:    r.State.isOpen(),
+		State:   string(r.State),
+		Service: r.Service,
+		Product: r.Product,
+		Banner:  r.Banner,
+
+		LatencyMS: float64(r.Latency) / float64(time.Millisecond),
+		Cert:      r.Cert,
+	}
+	if !utf8.ValidString(r.Banner) {
+		j.Banner = base64.StdEncoding.EncodeToString([]byte(r.Banner))
+		j.BannerEncoding = "base64"
+	}
+	return json.Marshal(j)
+}
+
+func (r *Result) UnmarshalJSON(data []byte) error {
+	var j resultJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	banner := j.Banner
+	if j.BannerEncoding == "base64" {
+		b, err := base64.StdEncoding.DecodeString(j.Banner)
+		if err != nil {
+			return fmt.Errorf("banner: %w", err)
+		}
+		banner = string(b)
+	}
+	*r = Result{
+		Host:    j.Host,
+		Port:    j.Port,
+		Proto:   j.Proto,
+		State:   portState(j.State),
+		Service: j.Service,
+		Product: j.Product,
+		Banner:  banner,
+		Latency: time.Duration(j.LatencyMS * float64(time.Millisecond)),
+		Cert:    j.Cert,
+	}
+	return nil
+}
+
+// annotate fills in the service name, fingerprint and banner of a probed
+// port.
+func annotate(r Result, banner []byte) Result {
+	r.Service = serviceName(r.Port, r.Proto)
+	r.Product = fingerprint(string(banner))
+	r.Banner = string(banner)
+	return r
+}
+
+// text formats r as a "<port> <state>" line, followed in verbose modes by
+// the dial latency, the service name, the fingerprinted product and the
+// escaped banner, with any TLS certificate on an indented second line.
+func (r Result) text(withService, withTiming bool) string {
+	s := fmt.Sprintf("%d %s", r.Port, r.State)
+	if withTiming {
+		s += fmt.Sprintf(" %.3fms", float64(r.Latency)/float64(time.Millisecond))
+	}
+	if withService && r.Service != "" {
+		s += " (" + r.Service + ")"
+	}
+	if r.Product != "" {
+		s += " [" + r.Product + "]"
+	}
+	if b := escapeBanner([]byte(r.Banner)); b != "" {
+		s += ": " + b
+	}
+	if r.Cert != nil {
+		s += "\n    cert: " + r.Cert.String()
+	}
+	return s
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServiceName(t *testing.T) {
+	for _, tc := range []struct {
+		port  int
+		proto string
+		want  string
+	}{
+		{22, "tcp", "ssh"},
+		{80, "tcp", "http"},
+		{53, "udp", "dns"},
+		{6379, "tcp", "redis"},
+		{64999, "tcp", ""},
+		{1, "xyz", ""},
+	} {
+		if got := serviceName(tc.port, tc.proto); got != tc.want {
+			t.Errorf("serviceName(%d, %q) = %q, want %q", tc.port, tc.proto, got, tc.want)
+		}
+	}
+}
+
+func TestServiceNameExtensible(t *testing.T) {
+	services["31337/tcp"] = "elite"
+	defer delete(services, "31337/tcp")
+	if got := serviceName(31337, "tcp"); got != "elite" {
+		t.Fatalf("serviceName(31337) = %q after adding it to services", got)
+	}
+}
+
+func TestResultJSONRoundTrip(t *testing.T) {
+	in := []Result{
+		{Host: "127.0.0.1", Port: 22, Proto: "tcp", State: stateOpen, Service: "ssh", Product: "OpenSSH 8.9p1",
+			Banner: "SSH-2.0-OpenSSH_8.9p1\r\n", Latency: 1500 * time.Microsecond},
+		{Host: "127.0.0.1", Port: 9999, Proto: "tcp", State: stateOpen, Banner: "\xff\xfe\x00binary"},
+		{Host: "::1", Port: 53, Proto: "udp", State: stateOpenFiltered, Service: "dns"},
+	}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !json.Valid(data) {
+		t.Fatalf("invalid JSON: %s", data)
+	}
+	if !strings.Contains(string(data), `"banner_encoding":"base64"`) {
+		t.Errorf("non-UTF-8 banner not flagged as base64: %s", data)
+	}
+	var out []Result
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Fatalf("round trip:\n got %+v\nwant %+v", out, in)
+	}
+	var raw []map[string]any
+	json.Unmarshal(data, &raw)
+	if raw[0]["open"] != true || raw[2]["open"] != true || raw[0]["latency_ms"] != 1.5 {
+		t.Errorf("unexpected fields: %v", raw)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// fullListener returns a loopback port whose accept queue is already full,
+// so the kernel drops further SYNs and dials to it time out, and a func
+// that closes the socket so later dials are refused instead.
+func fullListener(t *testing.T) (int, func()) {
+	t.Helper()
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_STREAM, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var once sync.Once
+	closeFn := func() { once.Do(func() { syscall.Close(fd) }) }
+	t.Cleanup(closeFn)
+	if err := syscall.Bind(fd, &syscall.SockaddrInet4{Addr: [4]byte{127, 0, 0, 1}}); err != nil {
+		t.Fatal(err)
+	}
+	// A backlog of 0 still queues one connection, which the dial below
+	// takes; nothing ever accepts it.
+	if err := syscall.Listen(fd, 0); err != nil {
+		t.Fatal(err)
+	}
+	sa, err := syscall.Getsockname(fd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := sa.(*syscall.SockaddrInet4).Port
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)), time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return port, closeFn
+}
+
+func TestProbeTimeoutThenRefusedIsFiltered(t *testing.T) {
+	port, closeListener := fullListener(t)
+	opts := scanOptions{timeout: 200 * time.Millisecond, retries: 1, backoff: 200 * time.Millisecond}
+	if state, _ := probe(context.Background(), "127.0.0.1", port, scanOptions{timeout: opts.timeout}); state != stateFiltered {
+		t.Skipf("dial to a full accept queue was %s, not a timeout, on this kernel", state)
+	}
+
+	// The first attempt times out; the socket is gone by the retry, which
+	// is refused.
+	go func() {
+		time.Sleep(opts.timeout + opts.backoff/2)
+		closeListener()
+	}()
+	if state, _ := probe(context.Background(), "127.0.0.1", port, opts); state != stateFiltered {
+		t.Fatalf("probe timing out then refused = %s, want filtered", state)
+	}
+	if state, _ := probe(context.Background(), "127.0.0.1", port, scanOptions{timeout: opts.timeout}); state != stateClosed {
+		t.Fatalf("port after the listener closed = %s, want the retry to have been refused", state)
+	}
+}
@@ -0,0 +1,378 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"errors"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// probe connects to a TCP port, retrying per opts, and reports its state
+// with the latency of the last attempt. A port is closed only if every
+// attempt was refused; a timeout or unreachable error on any of them,
+// typically a firewall dropping the SYN, means filtered.
+func probe(ctx context.Context, host string, port int, opts scanOptions) (portState, time.Duration) {
+	conn, state, latency := probeConn(ctx, host, port, opts)
+	if conn != nil {
+		conn.Close()
+	}
+	return state, latency
+}
+
+// probeConn is probe that hands back the connection to an open port, for
+// the caller to read banners over and close. Dialing again instead would
+// reuse the four-tuple of the connection just closed, which sits in
+// TIME_WAIT when every dial is bound to one source port.
+func probeConn(ctx context.Context, host string, port int, opts scanOptions) (net.Conn, portState, time.Duration) {
+	state := stateClosed
+	for attempt := 0; ; attempt++ {
+		start := time.Now()
+		conn, err := dial(ctx, "tcp", host, port, opts)
+		latency := time.Since(start)
+		if err == nil {
+			return conn, stateOpen, latency
+		}
+		if !errors.Is(err, syscall.ECONNREFUSED) {
+			state = stateFiltered
+		}
+		if attempt >= opts.retries || ctx.Err() != nil || !retryable(err) {
+			return nil, state, latency
+		}
+		select {
+		case <-time.After(opts.backoff):
+		case <-ctx.Done():
+			return nil, state, latency
+		}
+	}
+}
+
+func scan(ctx context.Context, host string, port int, opts scanOptions) bool {
+	state, _ := probe(ctx, host, port, opts)
+	return state == stateOpen
+}
+
+// scanTimed dials host:port once and returns whether it is open along with
+// how long the attempt took. Refused ports answer in about a round trip;
+// filtered ones take close to the full timeout.
+func scanTimed(host string, port int, timeout time.Duration) (bool, time.Duration) {
+	state, latency := probe(context.Background(), host, port, scanOptions{timeout: timeout})
+	return state == stateOpen, latency
+}
+
+// portState describes a probed port. UDP cannot always tell open from
+// filtered, and -expect splits open ports by their reply, so it needs more
+// than a bool.
+type portState string
+
+const (
+	stateOpen         portState = "open"
+	stateClosed       portState = "closed"
+	stateFiltered     portState = "filtered"
+	stateOpenFiltered portState = "open|filtered"
+	stateMatch        portState = "open+match"
+	stateNoMatch      portState = "open+nomatch"
+)
+
+// isOpen reports whether s is any of the states of a reachable port.
+func (s portState) isOpen() bool {
+	return s == stateOpen || s == stateOpenFiltered || s == stateMatch || s == stateNoMatch
+}
+
+// scanUDP sends an empty datagram to host:port and classifies the reply the
+// way nmap does: an ICMP port-unreachable (surfaced as a read error) means
+// closed, any reply means open, and silence means open|filtered.
+//
+// Silence is ambiguous: a firewall dropping the probe and a service that
+// ignores empty datagrams look the same, so open|filtered results are often
+// false positives.
+func scanUDP(ctx context.Context, host string, port int, opts scanOptions) portState {
+	conn, err := dial(ctx, "udp", host, port, opts)
+	if err != nil {
+		return stateClosed
+	}
+	defer conn.Close()
+	if _, err := conn.Write(nil); err != nil {
+		return stateClosed
+	}
+	conn.SetReadDeadline(time.Now().Add(opts.timeout))
+	buf := make([]byte, 1)
+	if _, err := conn.Read(buf); err != nil {
+		var ne net.Error
+		if errors.As(err, &ne) && ne.Timeout() {
+			return stateOpenFiltered
+		}
+		return stateClosed
+	}
+	return stateOpen
+}
+
+// target is one host:port pair handed to a worker. seq numbers targets
+// in the order they were handed out, from 0.
+type target struct {
+	host string
+	port int
+	seq  int
+}
+
+// hostFeed produces hosts one at a time until yield returns false, so a
+// sweep over a large network never holds every address at once. walkCIDR
+// matches this shape.
+type hostFeed func(yield func(host string) bool)
+
+func hostList(hosts ...string) hostFeed {
+	return func(yield func(string) bool) {
+		for _, h := range hosts {
+			if !yield(h) {
+				return
+			}
+		}
+	}
+}
+
+// forEachTarget calls fn for every port of every host in hosts from at most
+// workers goroutines and returns once they have all finished. The pool is
+// shared across hosts, so a /24 times 1000 ports still runs only workers
+// goroutines. Targets are handed out host by host, each host's ports in
+// the order given. Targets not yet handed out when ctx is cancelled are
+// skipped.
+func forEachTarget(ctx context.Context, hosts hostFeed, ports []int, workers int, fn func(t target)) {
+	if workers < 1 {
+		workers = 1
+	}
+	jobs := make(chan target)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for t := range jobs {
+				fn(t)
+			}
+		}()
+	}
+	seq := 0
+	hosts(func(host string) bool {
+		for _, port := range ports {
+			select {
+			case jobs <- target{host, port, seq}:
+				seq++
+			case <-ctx.Done():
+				return false
+			}
+		}
+		return true
+	})
+	close(jobs)
+	wg.Wait()
+}
+
+// compareHosts orders IP addresses numerically and anything else, such as
+// hostnames, as strings after them.
+func compareHosts(a, b string) int {
+	ipa, ipb := net.ParseIP(a), net.ParseIP(b)
+	switch {
+	case ipa != nil && ipb != nil:
+		return bytes.Compare(ipa.To16(), ipb.To16())
+	case ipa != nil:
+		return -1
+	case ipb != nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// sortResults orders results by host, then port.
+func sortResults(results []Result) {
+	sort.Slice(results, func(i, j int) bool {
+		if c := compareHosts(results[i].Host, results[j].Host); c != 0 {
+			return c < 0
+		}
+		return results[i].Port < results[j].Port
+	})
+}
+
+// probeTargets probes every port of every host using at most workers
+// concurrent connections and returns a result for each target handed out,
+// ordered by host then port. Cancelling ctx aborts outstanding dials;
+// targets probed before that are still returned, and those cut short are
+// left out.
+func probeTargets(ctx context.Context, hosts hostFeed, ports []int, workers int, opts scanOptions) []Result {
+	var (
+		mu      sync.Mutex
+		results []Result
+	)
+	forEachTarget(ctx, hosts, ports, workers, func(t target) {
+		state, latency := probe(ctx, t.host, t.port, opts)
+		if state != stateOpen && ctx.Err() != nil {
+			return // cut short, not filtered
+		}
+		mu.Lock()
+		results = append(results, Result{Host: t.host, Port: t.port, Proto: "tcp", State: state, Latency: latency})
+		mu.Unlock()
+	})
+	sortResults(results)
+	return results
+}
+
+// probePorts is probeTargets for a single host.
+func probePorts(ctx context.Context, host string, ports []int, workers int, opts scanOptions) []Result {
+	return probeTargets(ctx, hostList(host), ports, workers, opts)
+}
+
+// scanHosts scans ports on every host under one shared worker pool and
+// groups the open ports by host, each list in ascending order.
+func scanHosts(ctx context.Context, hosts []string, ports []int, workers int, opts scanOptions) map[string][]int {
+	open := make(map[string][]int)
+	for _, r := range probeTargets(ctx, hostList(hosts...), ports, workers, opts) {
+		if r.State == stateOpen {
+			open[r.Host] = append(open[r.Host], r.Port)
+		}
+	}
+	return open
+}
+
+// scanPorts dials ports on host using at most workers concurrent
+// connections and returns the open ones in ascending order.
+func scanPorts(ctx context.Context, host string, ports []int, workers int, opts scanOptions) []int {
+	var open []int
+	for _, r := range probePorts(ctx, host, ports, workers, opts) {
+		if r.State == stateOpen {
+			open = append(open, r.Port)
+		}
+	}
+	return open
+}
+
+// scanRange scans the inclusive port range start..end on host.
+func scanRange(ctx context.Context, host string, start, end, workers int, opts scanOptions) []int {
+	var ports []int
+	for port := start; port <= end; port++ {
+		ports = append(ports, port)
+	}
+	return scanPorts(ctx, host, ports, workers, opts)
+}
+
+// streamTargets probes ports on every host and sends each result on the
+// returned channel as soon as it and every target before it are known, so
+// long sweeps report open ports live instead of all at the end, in the same
+// order as a finished scan. TCP and UDP targets alike share one pool of
+// workers and the rate limiter in opts; open TCP ports have their banner
+// and TLS certificate grabbed right away when banner or cert is set. Only
+// open (or open|filtered) ports are sent unless all is set. The channel
+// closes once every target is done or ctx is cancelled and the outstanding
+// probes have returned; callers must drain it.
+func streamTargets(ctx context.Context, hosts hostFeed, proto string, ports []int, workers int, banner, cert, all bool, opts scanOptions) <-chan Result {
+	probe := func(t target) sequenced { return probeTarget(ctx, t, banner, cert, all, opts) }
+	if proto == "udp" {
+		probe = func(t target) sequenced { return probeUDPTarget(ctx, t, all, opts) }
+	}
+	out := make(chan Result)
+	go func() {
+		defer close(out)
+		done := make(chan sequenced)
+		go func() {
+			defer close(done)
+			forEachTarget(ctx, hosts, ports, workers, func(t target) {
+				done <- probe(t)
+			})
+		}()
+		inOrder(done, out)
+	}()
+	return out
+}
+
+// sequenced is a worker's outcome for the target numbered seq: a result
+// to report, or nothing when ok is false.
+type sequenced struct {
+	seq int
+	r   Result
+	ok  bool
+}
+
+// probeTarget probes one TCP target for streamTargets. An open port's
+// connection is then used for the exchange if opts has one, or else for a
+// TLS handshake when cert is set or the port's opener when banner is. With
+// cert, the banner is whatever the port answers a ClientHello with. Ports
+// that are not open are only reported when all is set, and never once ctx
+// is cancelled, since their state may just be the cut-short dial.
+func probeTarget(ctx context.Context, t target, banner, cert, all bool, opts scanOptions) sequenced {
+	conn, state, latency := probeConn(ctx, t.host, t.port, opts)
+	if state != stateOpen && (!all || ctx.Err() != nil) {
+		return sequenced{seq: t.seq}
+	}
+	r := Result{Host: t.host, Port: t.port, Proto: "tcp", State: state, Latency: latency}
+	if conn == nil {
+		return sequenced{t.seq, annotate(r, nil), true}
+	}
+	defer conn.Close()
+	var b []byte
+	switch {
+	case opts.send != nil || opts.expect != nil:
+		var matched bool
+		b, matched = exchange(conn, opts)
+		if opts.expect != nil {
+			r.State = stateNoMatch
+			if matched {
+				r.State = stateMatch
+			}
+		}
+	case cert:
+		var c *x509.Certificate
+		c, b = handshake(ctx, conn, t.host, opts.timeout)
+		if c != nil {
+			r.Cert = newCertInfo(c)
+		}
+		if !banner {
+			b = nil
+		}
+	case banner:
+		b = probeService(conn, t.port, opts.timeout)
+	}
+	return sequenced{t.seq, annotate(r, b), true}
+}
+
+// probeUDPTarget probes one UDP target for streamTargets. Closed ports are
+// only reported when all is set, and nothing is once ctx is cancelled.
+func probeUDPTarget(ctx context.Context, t target, all bool, opts scanOptions) sequenced {
+	start := time.Now()
+	state := scanUDP(ctx, t.host, t.port, opts)
+	if ctx.Err() != nil || state == stateClosed && !all {
+		return sequenced{seq: t.seq}
+	}
+	r := Result{Host: t.host, Port: t.port, Proto: "udp", State: state, Latency: time.Since(start)}
+	return sequenced{t.seq, annotate(r, nil), true}
+}
+
+// inOrder forwards the outcomes arriving on done to out in seq order,
+// holding each until every target numbered before it has finished. Every
+// target handed out must arrive exactly once.
+func inOrder(done <-chan sequenced, out chan<- Result) {
+	held := make(map[int]sequenced)
+	next := 0
+	for s := range done {
+		held[s.seq] = s
+		for {
+			h, ok := held[next]
+			if !ok {
+				break
+			}
+			delete(held, next)
+			next++
+			if h.ok {
+				out <- h.r
+			}
+		}
+	}
+}
+
+// scanStream streams the open TCP ports of host as they are confirmed; see
+// streamTargets.
+func scanStream(ctx context.Context, host string, ports []int, workers int, opts scanOptions) <-chan Result {
+	return streamTargets(ctx, hostList(host), "tcp", ports, workers, false, false, false, opts)
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// listen opens a TCP listener on an ephemeral loopback port that accepts
+// and immediately closes connections, and returns its port.
+func listen(t *testing.T) int {
+	t.Helper()
+	return listenOn(t, "127.0.0.1")
+}
+
+// listenOn is listen on a given local address, skipping the test if it
+// cannot be bound.
+func listenOn(t *testing.T, host string) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", net.JoinHostPort(host, "0"))
+	if err != nil {
+		t.Skipf("cannot listen on %s: %v", host, err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+// closedPort returns a loopback port that nothing listens on.
+func closedPort(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+	return port
+}
+
+// listenRun opens n listeners on consecutive loopback ports and returns the
+// first, skipping the test if no such run comes free.
+func listenRun(t *testing.T, n int) int {
+	t.Helper()
+	for try := 0; try < 20; try++ {
+		var lns []net.Listener
+		first := 0
+		for len(lns) < n {
+			ln, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(first+len(lns))))
+			if err != nil {
+				break
+			}
+			if first == 0 {
+				first = ln.Addr().(*net.TCPAddr).Port
+			}
+			lns = append(lns, ln)
+		}
+		if len(lns) == n {
+			for _, ln := range lns {
+				t.Cleanup(func() { ln.Close() })
+			}
+			return first
+		}
+		for _, ln := range lns {
+			ln.Close()
+		}
+	}
+	t.Skipf("no run of %d free consecutive ports", n)
+	return 0
+}
+
+var testOpts = scanOptions{timeout: time.Second}
+
+func TestScanPortsFindsListeners(t *testing.T) {
+	want := []int{listen(t), listen(t), listen(t)}
+	closed := closedPort(t)
+	ports := append([]int{closed}, want...)
+
+	got := scanPorts(context.Background(), "127.0.0.1", ports, 2, testOpts)
+	sort.Ints(want)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("scanPorts = %v, want %v", got, want)
+	}
+}
+
+func TestScanRange(t *testing.T) {
+	first := listenRun(t, 3)
+	got := scanRange(context.Background(), "127.0.0.1", first-1, first+3, 3, testOpts)
+	want := []int{first, first + 1, first + 2}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("scanRange(%d, %d) = %v, want %v", first-1, first+3, got, want)
+	}
+}
+
+func TestDeadlineStopsUnroutableScan(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	// 192.0.2.0/24 is TEST-NET-1: dials there hang until they time out.
+	scanPorts(ctx, "192.0.2.1", []int{22, 80, 443, 8080}, 2, scanOptions{timeout: 5 * time.Second})
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("scan took %v with a 100ms deadline, want it to return promptly", elapsed)
+	}
+}
+
+func TestDeadlineKeepsPartialResults(t *testing.T) {
+	open := listen(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	got := probeTargets(ctx, hostList("127.0.0.1", "192.0.2.1"), []int{open}, 2, scanOptions{timeout: 5 * time.Second})
+	if len(got) == 0 || got[0].Host != "127.0.0.1" || got[0].State != stateOpen {
+		t.Fatalf("probeTargets = %+v, want the open loopback port first", got)
+	}
+	for _, r := range got[1:] {
+		if r.State == stateFiltered {
+			t.Errorf("probe cut short by the deadline reported as %+v", r)
+		}
+	}
+}
+
+func TestProbeRetriesUntilListenerComesUp(t *testing.T) {
+	port := closedPort(t)
+	addr := net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+	opts := scanOptions{timeout: time.Second, retries: 3, backoff: 100 * time.Millisecond}
+
+	// The first dial goes out at once and is refused; the listener is up
+	// well before the retry after the backoff.
+	up := make(chan net.Listener, 1)
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			t.Error(err)
+		}
+		up <- ln
+	}()
+	state, _ := probe(context.Background(), "127.0.0.1", port, opts)
+	if ln := <-up; ln != nil {
+		ln.Close()
+	}
+	if state != stateOpen {
+		t.Fatalf("probe with retries = %s, want open", state)
+	}
+}
+
+func TestProbeWithoutRetriesIsClosed(t *testing.T) {
+	port := closedPort(t)
+	start := time.Now()
+	state, latency := probe(context.Background(), "127.0.0.1", port, scanOptions{timeout: time.Second, backoff: time.Second})
+	if state != stateClosed {
+		t.Fatalf("probe = %s, want closed", state)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Fatalf("probe without retries took %v; latency %v", time.Since(start), latency)
+	}
+}
+
+func TestScanTimedRefusedIsFast(t *testing.T) {
+	const timeout = 2 * time.Second
+	open, latency := scanTimed("127.0.0.1", closedPort(t), timeout)
+	if open {
+		t.Fatal("closed port reported open")
+	}
+	if latency <= 0 || latency >= timeout/4 {
+		t.Fatalf("refused dial latency = %v, want well under the %v timeout", latency, timeout)
+	}
+	open, latency = scanTimed("127.0.0.1", listen(t), timeout)
+	if !open || latency <= 0 {
+		t.Fatalf("listener = open %v latency %v", open, latency)
+	}
+}
+
+func TestScanHostsGroupsByHost(t *testing.T) {
+	a1, a2 := listenOn(t, "127.0.0.1"), listenOn(t, "127.0.0.1")
+	b := listenOn(t, "127.0.0.2")
+	if b == a1 || b == a2 {
+		t.Skip("both loopback addresses were given the same port")
+	}
+	closed := closedPort(t)
+	ports := []int{closed, a1, a2, b}
+
+	got := scanHosts(context.Background(), []string{"127.0.0.2", "127.0.0.1"}, ports, 3, testOpts)
+	want := map[string][]int{"127.0.0.1": {a1, a2}, "127.0.0.2": {b}}
+	sort.Ints(want["127.0.0.1"])
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("scanHosts = %v, want %v", got, want)
+	}
+}
+
+func TestScanStreamReportsEachOpenPort(t *testing.T) {
+	open := map[int]bool{listen(t): true, listen(t): true, listen(t): true}
+	ports := []int{closedPort(t)}
+	for p := range open {
+		ports = append(ports, p)
+	}
+
+	n := 0
+	for r := range scanStream(context.Background(), "127.0.0.1", ports, 4, testOpts) {
+		if !open[r.Port] || r.State != stateOpen {
+			t.Errorf("streamed %d %s, want only the listeners", r.Port, r.State)
+		}
+		n++
+	}
+	if n != len(open) {
+		t.Fatalf("streamed %d results, want %d", n, len(open))
+	}
+}
+
+func TestScanStreamKeepsPortOrder(t *testing.T) {
+	ports := []int{listen(t), listen(t), listen(t), listen(t), listen(t)}
+	sort.Sort(sort.Reverse(sort.IntSlice(ports)))
+	var got []int
+	for r := range scanStream(context.Background(), "127.0.0.1", ports, 5, testOpts) {
+		got = append(got, r.Port)
+	}
+	if !reflect.DeepEqual(got, ports) {
+		t.Fatalf("streamed ports %v, want them in the order asked, %v", got, ports)
+	}
+}
+
+func TestInOrderHoldsLaterTargets(t *testing.T) {
+	done := make(chan sequenced)
+	out := make(chan Result)
+	go func() {
+		defer close(out)
+		inOrder(done, out)
+	}()
+	go func() {
+		defer close(done)
+		// Target 2 finishes first and target 1 has nothing to report.
+		for _, seq := range []int{2, 0, 3, 1} {
+			done <- sequenced{seq, Result{Port: seq}, seq != 1}
+		}
+	}()
+	var got []int
+	for r := range out {
+		got = append(got, r.Port)
+	}
+	if want := []int{0, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("inOrder released %v, want %v", got, want)
+	}
+}
+
+func TestStreamCancelClosesChannel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	closed := closedPort(t)
+	ports := make([]int, 1000)
+	for i := range ports {
+		ports[i] = closed
+	}
+	stream := scanStream(ctx, "127.0.0.1", ports, 2, testOpts)
+	select {
+	case _, ok := <-stream:
+		if ok {
+			t.Fatal("cancelled scan reported a closed port")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("channel still open after cancel")
+	}
+}
+
+func TestCancelMidScanKeepsPartialResults(t *testing.T) {
+	open := listen(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// The loopback port answers at once; the TEST-NET-1 dials hang until
+	// the cancel, as a long scan does when Ctrl-C arrives.
+	hosts := hostList("127.0.0.1", "192.0.2.1")
+	stream := streamTargets(ctx, hosts, "tcp", []int{open}, 2, false, false, false, scanOptions{timeout: 5 * time.Second})
+
+	var got []Result
+	timeout := time.After(5 * time.Second)
+	for done := false; !done; {
+		select {
+		case r, ok := <-stream:
+			if !ok {
+				done = true
+				break
+			}
+			got = append(got, r)
+			cancel()
+		case <-timeout:
+			t.Fatal("stream still open 5s after cancel")
+		}
+	}
+	if len(got) == 0 || got[0].Host != "127.0.0.1" || got[0].Port != open || got[0].State != stateOpen {
+		t.Fatalf("results after cancel = %+v, want the open loopback port", got)
+	}
+	for _, r := range got[1:] {
+		if r.State == stateFiltered {
+			t.Errorf("probe cut short by the cancel reported as %+v", r)
+		}
+	}
+}
+
+// silentUDP binds a loopback UDP port that never answers, so probes of it
+// wait out the timeout, and returns its port.
+func silentUDP(t *testing.T) int {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pc.Close() })
+	return pc.LocalAddr().(*net.UDPAddr).Port
+}
+
+func TestStreamUDPSharesWorkerPool(t *testing.T) {
+	const timeout = 300 * time.Millisecond
+	ports := []int{silentUDP(t), silentUDP(t), silentUDP(t), silentUDP(t)}
+	start := time.Now()
+	var got []int
+	for r := range streamTargets(context.Background(), hostList("127.0.0.1"), "udp", ports, len(ports), false, false, false, scanOptions{timeout: timeout}) {
+		if r.State != stateOpenFiltered {
+			t.Errorf("silent UDP port %d = %s, want open|filtered", r.Port, r.State)
+		}
+		got = append(got, r.Port)
+	}
+	if !reflect.DeepEqual(got, ports) {
+		t.Fatalf("streamed ports %v, want %v in order", got, ports)
+	}
+	// One at a time the four timeouts would add up to 1.2s.
+	if elapsed := time.Since(start); elapsed > 2*timeout {
+		t.Fatalf("4 UDP probes on 4 workers took %v, want about one %v timeout", elapsed, timeout)
+	}
+}
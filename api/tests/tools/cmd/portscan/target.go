@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// resolveTarget normalizes a command-line target, accepting IPv4 and IPv6
+// literals (optionally bracketed, e.g. "[::1]") or a resolvable hostname.
+func resolveTarget(target string) (string, error) {
+	host := strings.TrimSuffix(strings.TrimPrefix(target, "["), "]")
+	if host == "" {
+		return "", fmt.Errorf("empty host")
+	}
+	if net.ParseIP(host) != nil {
+		return host, nil
+	}
+	if _, err := net.LookupHost(host); err != nil {
+		return "", fmt.Errorf("cannot resolve %q: %w", target, err)
+	}
+	return host, nil
+}
+
+// walkCIDR calls fn with each usable host address in ipnet, in order, until
+// fn returns false. Addresses are generated one at a time so even a /8 costs
+// no memory. For IPv4 prefixes shorter than /31 the network and broadcast
+// addresses are skipped.
+func walkCIDR(ipnet *net.IPNet, fn func(host string) bool) {
+	ip := ipnet.IP.Mask(ipnet.Mask)
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+	}
+	ones, bits := ipnet.Mask.Size()
+	skipEdges := bits == 32 && ones < 31
+	cur := make(net.IP, len(ip))
+	copy(cur, ip)
+	for ipnet.Contains(cur) {
+		next := make(net.IP, len(cur))
+		copy(next, cur)
+		last := !increment(next) || !ipnet.Contains(next)
+		isEdge := cur.Equal(ip) || last
+		if !(skipEdges && isEdge) && !fn(cur.String()) {
+			return
+		}
+		if last {
+			return
+		}
+		cur = next
+	}
+}
+
+// increment adds one to ip in place, reporting false on wrap-around.
+func increment(ip net.IP) bool {
+	for i := len(ip) - 1; i >= 0; i-- {
+		ip[i]++
+		if ip[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// expandCIDR lists every usable host address in cidr. Prefer walkCIDR for
+// large networks.
+func expandCIDR(cidr string) ([]string, error) {
+	_, ipnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, err
+	}
+	var hosts []string
+	walkCIDR(ipnet, func(host string) bool {
+		hosts = append(hosts, host)
+		return true
+	})
+	return hosts, nil
+}
+
+// parsePorts parses a -p port list: comma-separated ports and inclusive
+// ranges such as "22,80,8000-8100". As in Nmap, a range may leave out
+// either end, so "-1024" starts at 1, "60000-" runs to 65535 and "-" is
+// every port. Ports come back in the order given, without repeats.
+func parsePorts(spec string) ([]int, error) {
+	var ports []int
+	seen := make(map[int]bool)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		lo, hi, isRange := strings.Cut(part, "-")
+		start, err := parsePort(lo, 1, isRange)
+		if err != nil {
+			return nil, fmt.Errorf("bad port %q: %w", part, err)
+		}
+		end := start
+		if isRange {
+			if end, err = parsePort(hi, 65535, true); err != nil {
+				return nil, fmt.Errorf("bad port range %q: %w", part, err)
+			}
+			if end < start {
+				return nil, fmt.Errorf("bad port range %q: end is before start", part)
+			}
+		}
+		for p := start; p <= end; p++ {
+			if !seen[p] {
+				seen[p] = true
+				ports = append(ports, p)
+			}
+		}
+	}
+	return ports, nil
+}
+
+// parsePort parses one end of a -p entry, returning def for an end left
+// out of a range.
+func parsePort(s string, def int, inRange bool) (int, error) {
+	if s == "" && inRange {
+		return def, nil
+	}
+	p, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("not a number")
+	}
+	if p < 1 || p > 65535 {
+		return 0, fmt.Errorf("%d is outside 1-65535", p)
+	}
+	return p, nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestResolveTarget(t *testing.T) {
+	for _, tc := range []struct{ in, want string }{
+		{"127.0.0.1", "127.0.0.1"},
+		{"[::1]", "::1"},
+		{"::1", "::1"},
+		{"localhost", "localhost"},
+	} {
+		got, err := resolveTarget(tc.in)
+		if err != nil || got != tc.want {
+			t.Errorf("resolveTarget(%q) = %q, %v; want %q", tc.in, got, err, tc.want)
+		}
+	}
+	if _, err := resolveTarget("[]"); err == nil {
+		t.Error("resolveTarget(\"[]\") succeeded, want an error")
+	}
+}
+
+func TestScanIPv4AndIPv6(t *testing.T) {
+	for _, target := range []string{"127.0.0.1", "[::1]"} {
+		host, err := resolveTarget(target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ln, err := net.Listen("tcp", net.JoinHostPort(host, "0"))
+		if err != nil {
+			t.Logf("skipping %s: %v", target, err)
+			continue
+		}
+		port := ln.Addr().(*net.TCPAddr).Port
+		if !scan(context.Background(), host, port, testOpts) {
+			t.Errorf("scan(%s, %d) = false with a listener up", target, port)
+		}
+		ln.Close()
+		if scan(context.Background(), host, port, testOpts) {
+			t.Errorf("scan(%s, %d) = true after the listener closed", target, port)
+		}
+	}
+}
+
+func TestExpandCIDR(t *testing.T) {
+	for _, tc := range []struct {
+		cidr string
+		want []string
+	}{
+		{"10.0.0.0/30", []string{"10.0.0.1", "10.0.0.2"}},
+		{"10.0.0.5/30", []string{"10.0.0.5", "10.0.0.6"}},
+		{"10.0.0.0/31", []string{"10.0.0.0", "10.0.0.1"}},
+		{"10.0.0.7/32", []string{"10.0.0.7"}},
+		{"192.168.0.254/23", nil}, // checked by count below
+		{"fd00::/126", []string{"fd00::", "fd00::1", "fd00::2", "fd00::3"}},
+	} {
+		got, err := expandCIDR(tc.cidr)
+		if err != nil {
+			t.Errorf("expandCIDR(%q): %v", tc.cidr, err)
+			continue
+		}
+		if tc.want == nil {
+			if len(got) != 510 || got[0] != "192.168.0.1" || got[509] != "192.168.1.254" {
+				t.Errorf("expandCIDR(%q) = %d hosts %s..%s", tc.cidr, len(got), got[0], got[len(got)-1])
+			}
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("expandCIDR(%q) = %v, want %v", tc.cidr, got, tc.want)
+		}
+	}
+	if _, err := expandCIDR("10.0.0.0/33"); err == nil {
+		t.Error("expandCIDR accepted a /33")
+	}
+}
+
+func TestWalkCIDRStops(t *testing.T) {
+	_, ipnet, _ := net.ParseCIDR("10.0.0.0/8")
+	var seen []string
+	walkCIDR(ipnet, func(h string) bool {
+		seen = append(seen, h)
+		return len(seen) < 3
+	})
+	if want := []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}; !reflect.DeepEqual(seen, want) {
+		t.Fatalf("walkCIDR visited %v, want %v then stop", seen, want)
+	}
+}
+
+func TestParsePorts(t *testing.T) {
+	for _, tc := range []struct {
+		spec string
+		want []int
+	}{
+		{"22", []int{22}},
+		{"22,80,443", []int{22, 80, 443}},
+		{"443, 22 ,8000-8003", []int{443, 22, 8000, 8001, 8002, 8003}},
+		{"80,79-81,80", []int{80, 79, 81}},
+		{"-3", []int{1, 2, 3}},
+		{"65533-", []int{65533, 65534, 65535}},
+		{"7-7", []int{7}},
+	} {
+		got, err := parsePorts(tc.spec)
+		if err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("parsePorts(%q) = %v, %v; want %v", tc.spec, got, err, tc.want)
+		}
+	}
+	if all, err := parsePorts("-"); err != nil || len(all) != 65535 || all[0] != 1 || all[65534] != 65535 {
+		t.Errorf("parsePorts(\"-\") = %d ports, %v; want 1-65535", len(all), err)
+	}
+}
+
+func TestParsePortsErrors(t *testing.T) {
+	for _, spec := range []string{"", "http", "22,", "0", "65536", "80-70", "1-2-3", "22,x-25", "-1-5", "1-99999"} {
+		if got, err := parsePorts(spec); err == nil {
+			t.Errorf("parsePorts(%q) = %v, want an error", spec, got)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+// Rot applies ROT47, or with -n any rotation of the printable ASCII range,
+// to stdin.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dnakov/ctf-arena/api/tests/tools/cipher"
+)
+
+func main() {
+	n := flag.Int("n", 47, "rotation amount over the printable ASCII range")
+	flag.Parse()
+
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "rot: reading stdin: %v\n", err)
+		os.Exit(1)
+	}
+	io.WriteString(os.Stdout, cipher.ROTN(string(data), *n))
+}
@@ -0,0 +1,56 @@
+// Strings prints the runs of printable characters in a file or stdin, in
+// ASCII or with -e l UTF-16LE, like strings(1).
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dnakov/ctf-arena/api/tests/tools/extract"
+)
+
+func main() {
+	n := flag.Int("n", 4, "minimum string length in characters")
+	enc := flag.String("e", "s", "encoding: s for 7-bit ASCII, l for UTF-16LE")
+	radix := flag.String("t", "", "print each string's offset in this radix: d, o or x")
+	flag.Parse()
+
+	formats := map[string]string{"": "", "d": "%7d ", "o": "%7o ", "x": "%7x "}
+	offFormat, ok := formats[*radix]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "strings: unknown radix %q\n", *radix)
+		os.Exit(2)
+	}
+	if *enc != "s" && *enc != "l" {
+		fmt.Fprintf(os.Stderr, "strings: unknown encoding %q\n", *enc)
+		os.Exit(2)
+	}
+
+	var in io.Reader = os.Stdin
+	if flag.NArg() > 0 {
+		f, err := os.Open(flag.Arg(0))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "strings: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		in = f
+	}
+	data, err := io.ReadAll(in)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "strings: reading input: %v\n", err)
+		os.Exit(1)
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+	for _, s := range extract.Strings(data, max(*n, 1), *enc == "l") {
+		if offFormat != "" {
+			fmt.Fprintf(w, offFormat, s.Offset)
+		}
+		fmt.Fprintln(w, s.S)
+	}
+}
@@ -0,0 +1,78 @@
+// Urlenc percent-decodes stdin as a query component or path segment, or
+// encodes it with -e.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+)
+
+func isHexDigit(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+// checkEscapes reports the first '%' not followed by two hex digits, so
+// errors point at a byte offset instead of echoing the whole input back.
+func checkEscapes(s string) error {
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			continue
+		}
+		if i+2 >= len(s) || !isHexDigit(s[i+1]) || !isHexDigit(s[i+2]) {
+			end := min(i+3, len(s))
+			return fmt.Errorf("malformed escape %q at byte %d", s[i:end], i)
+		}
+		i += 2
+	}
+	return nil
+}
+
+// unescape decodes s as a query component (where '+' means space) or as a
+// path segment (where '+' is literal).
+func unescape(s, component string) (string, error) {
+	if err := checkEscapes(s); err != nil {
+		return "", err
+	}
+	if component == "path" {
+		return url.PathUnescape(s)
+	}
+	return url.QueryUnescape(s)
+}
+
+func escape(s, component string) string {
+	if component == "path" {
+		return url.PathEscape(s)
+	}
+	return url.QueryEscape(s)
+}
+
+func main() {
+	encode := flag.Bool("e", false, "encode stdin instead of decoding it")
+	component := flag.String("component", "query", "escaping rules: query ('+' is space) or path")
+	flag.Parse()
+
+	if *component != "query" && *component != "path" {
+		fmt.Fprintf(os.Stderr, "urlenc: unknown component %q\n", *component)
+		os.Exit(2)
+	}
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "urlenc: reading stdin: %v\n", err)
+		os.Exit(1)
+	}
+	s := strings.TrimRight(string(data), "\r\n")
+	if *encode {
+		fmt.Print(escape(s, *component))
+		return
+	}
+	decoded, err := unescape(s, *component)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "urlenc: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Print(decoded)
+}
@@ -0,0 +1,50 @@
+package main
+
+import "testing"
+
+func TestUnescapeDoubleEncoded(t *testing.T) {
+	// flag{a b} encoded twice as a query component.
+	in := "flag%257Ba%2Bb%257D"
+	once, err := unescape(in, "query")
+	if err != nil || once != "flag%7Ba+b%7D" {
+		t.Fatalf("first pass = %q, %v", once, err)
+	}
+	twice, err := unescape(once, "query")
+	if err != nil || twice != "flag{a b}" {
+		t.Fatalf("second pass = %q, %v", twice, err)
+	}
+	if got := escape(escape("flag{a b}", "query"), "query"); got != in {
+		t.Fatalf("double escape = %q, want %q", got, in)
+	}
+}
+
+func TestPathReservedCharacters(t *testing.T) {
+	seg := "a b+c/d?e#f&g=h"
+	enc := escape(seg, "path")
+	if enc != "a%20b+c%2Fd%3Fe%23f&g=h" {
+		t.Fatalf("PathEscape = %q", enc)
+	}
+	if got, err := unescape(enc, "path"); err != nil || got != seg {
+		t.Fatalf("path round trip = %q, %v", got, err)
+	}
+	// The same '+' is a space in a query but literal in a path.
+	if q, _ := unescape("a+b", "query"); q != "a b" {
+		t.Errorf("query unescape(a+b) = %q", q)
+	}
+	if p, _ := unescape("a+b", "path"); p != "a+b" {
+		t.Errorf("path unescape(a+b) = %q", p)
+	}
+}
+
+func TestUnescapeMalformed(t *testing.T) {
+	for _, tc := range []struct{ in, want string }{
+		{"abc%zz", `malformed escape "%zz" at byte 3`},
+		{"flag%7", `malformed escape "%7" at byte 4`},
+		{"%", `malformed escape "%" at byte 0`},
+	} {
+		_, err := unescape(tc.in, "query")
+		if err == nil || err.Error() != tc.want {
+			t.Errorf("unescape(%q) error = %v, want %q", tc.in, err, tc.want)
+		}
+	}
+}
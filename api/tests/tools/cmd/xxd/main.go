@@ -0,0 +1,67 @@
+// Xxd prints stdin in the canonical hexdump -C layout.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+)
+
+// dump writes data in the canonical `hexdump -C` layout: an offset, 16 hex
+// bytes split into two groups of 8 and an ASCII gutter. Runs of identical
+// lines collapse to "*" and the total length ends the dump, as hexdump does,
+// so the output diffs cleanly against it.
+func dump(data []byte, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	var prev []byte
+	squeezed := false
+	for off := 0; off < len(data); off += 16 {
+		line := data[off:min(off+16, len(data))]
+		if len(line) == 16 && bytes.Equal(line, prev) {
+			if !squeezed {
+				bw.WriteString("*\n")
+				squeezed = true
+			}
+			continue
+		}
+		prev, squeezed = line, false
+
+		fmt.Fprintf(bw, "%08x  ", off)
+		for i := 0; i < 16; i++ {
+			if i < len(line) {
+				fmt.Fprintf(bw, "%02x ", line[i])
+			} else {
+				bw.WriteString("   ")
+			}
+			if i == 7 {
+				bw.WriteByte(' ')
+			}
+		}
+		bw.WriteString(" |")
+		for _, c := range line {
+			if c < 0x20 || c >= 0x7f {
+				c = '.'
+			}
+			bw.WriteByte(c)
+		}
+		bw.WriteString("|\n")
+	}
+	if len(data) > 0 {
+		fmt.Fprintf(bw, "%08x\n", len(data))
+	}
+	return bw.Flush()
+}
+
+func main() {
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "xxd: reading stdin: %v\n", err)
+		os.Exit(1)
+	}
+	if err := dump(data, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "xxd: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/*.golden from the current output")
+
+func TestDumpGolden(t *testing.T) {
+	data, err := os.ReadFile("testdata/short.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got bytes.Buffer
+	if err := dump(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if *update {
+		if err := os.WriteFile("testdata/short.golden", got.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile("testdata/short.golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Bytes(), want) {
+		t.Fatalf("dump mismatch\n got:\n%s\nwant:\n%s", got.Bytes(), want)
+	}
+}
+
+func TestDumpEmpty(t *testing.T) {
+	var got bytes.Buffer
+	if err := dump(nil, &got); err != nil || got.Len() != 0 {
+		t.Fatalf("dump(nil) = %q, %v; want no output", got.String(), err)
+	}
+}
@@ -0,0 +1,129 @@
+// Package decode peels encodings off CTF payloads.
+package decode
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/url"
+	"regexp"
+)
+
+// transform is one reversible encoding AutoDecode knows how to undo.
+type transform struct {
+	name   string
+	decode func([]byte) ([]byte, error)
+}
+
+var errNotApplicable = errors.New("not applicable")
+
+var urlEscape = regexp.MustCompile(`%[0-9A-Fa-f]{2}`)
+
+// transforms is tried in order; on a tie in printability the earlier entry
+// wins, which is why the strict formats come before the lenient ones.
+var transforms = []transform{
+	{"gzip", gunzip},
+	{"hex", func(b []byte) ([]byte, error) {
+		return hex.DecodeString(string(bytes.TrimSpace(b)))
+	}},
+	{"base64", func(b []byte) ([]byte, error) {
+		s := string(bytes.TrimSpace(b))
+		if out, err := base64.StdEncoding.DecodeString(s); err == nil {
+			return out, nil
+		}
+		return base64.RawStdEncoding.DecodeString(s)
+	}},
+	{"base64url", func(b []byte) ([]byte, error) {
+		s := string(bytes.TrimSpace(b))
+		if out, err := base64.URLEncoding.DecodeString(s); err == nil {
+			return out, nil
+		}
+		return base64.RawURLEncoding.DecodeString(s)
+	}},
+	{"url", func(b []byte) ([]byte, error) {
+		if !urlEscape.Match(b) {
+			return nil, errNotApplicable
+		}
+		s, err := url.QueryUnescape(string(b))
+		return []byte(s), err
+	}},
+}
+
+func gunzip(b []byte) ([]byte, error) {
+	if !bytes.HasPrefix(b, []byte{0x1f, 0x8b}) {
+		return nil, errNotApplicable
+	}
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+// score rates a candidate decoding by printability. Binary that is itself a
+// valid gzip stream is scored by its decompressed contents, since that is
+// what the next round will see.
+func score(b []byte) float64 {
+	if inner, err := gunzip(b); err == nil {
+		return Printable(inner)
+	}
+	return Printable(b)
+}
+
+// Printable returns the fraction of data that is printable ASCII or common
+// whitespace. Empty input scores 0.
+func Printable(data []byte) float64 {
+	if len(data) == 0 {
+		return 0
+	}
+	n := 0
+	for _, c := range data {
+		if c >= 0x20 && c < 0x7f || c == '\n' || c == '\r' || c == '\t' {
+			n++
+		}
+	}
+	return float64(n) / float64(len(data))
+}
+
+// AutoDecode repeatedly applies whichever transform decodes data without
+// error into something at least as printable as before, preferring the
+// most printable result. It stops when nothing applies or after maxRounds,
+// and returns the final bytes with the names of the transforms applied in
+// order.
+func AutoDecode(data []byte, maxRounds int) ([]byte, []string) {
+	return AutoDecodeUntil(data, maxRounds, nil)
+}
+
+// AutoDecodeUntil is AutoDecode with an extra stop condition checked
+// before each round, such as flagfmt.Found, so decoding halts as soon as a
+// flag surfaces instead of mangling it further. A nil stop never fires.
+func AutoDecodeUntil(data []byte, maxRounds int, stop func([]byte) bool) ([]byte, []string) {
+	var trail []string
+	for round := 0; round < maxRounds; round++ {
+		if stop != nil && stop(data) {
+			break
+		}
+		current := score(data)
+		var best []byte
+		var bestName string
+		bestScore := -1.0
+		for _, t := range transforms {
+			out, err := t.decode(data)
+			if err != nil || len(out) == 0 || bytes.Equal(out, data) {
+				continue
+			}
+			if s := score(out); s >= current && s > bestScore {
+				best, bestName, bestScore = out, t.name, s
+			}
+		}
+		if best == nil {
+			break
+		}
+		data = best
+		trail = append(trail, bestName)
+	}
+	return data, trail
+}
@@ -0,0 +1,73 @@
+package decode
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/hex"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/dnakov/ctf-arena/api/tests/tools/flagfmt"
+)
+
+func gzipped(t *testing.T, b []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(b); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestAutoDecodeTripleWrap(t *testing.T) {
+	wrapped := []byte(hex.EncodeToString([]byte("flag{test}")))
+	wrapped = []byte(base64.StdEncoding.EncodeToString(wrapped))
+	wrapped = gzipped(t, wrapped)
+
+	got, trail := AutoDecode(wrapped, 10)
+	if string(got) != "flag{test}" {
+		t.Fatalf("AutoDecode = %q, want %q", got, "flag{test}")
+	}
+	if want := []string{"gzip", "base64", "hex"}; !reflect.DeepEqual(trail, want) {
+		t.Fatalf("trail = %v, want %v", trail, want)
+	}
+}
+
+func TestAutoDecodeMaxRounds(t *testing.T) {
+	wrapped := url.QueryEscape(base64.StdEncoding.EncodeToString([]byte("flag{x}")))
+	got, trail := AutoDecode([]byte(wrapped), 1)
+	if len(trail) != 1 || trail[0] != "url" {
+		t.Fatalf("one round trail = %v, want [url]", trail)
+	}
+	if string(got) != base64.StdEncoding.EncodeToString([]byte("flag{x}")) {
+		t.Fatalf("one round = %q", got)
+	}
+}
+
+func TestAutoDecodeUntilFlag(t *testing.T) {
+	// The flag's own %25 would be URL-decoded without the stop condition.
+	inner := []byte("flag{100%25_legit}")
+	wrapped := []byte(base64.StdEncoding.EncodeToString(inner))
+	if got, _ := AutoDecode(wrapped, 5); string(got) != "flag{100%_legit}" {
+		t.Fatalf("AutoDecode = %q, want the flag mangled", got)
+	}
+	got, trail := AutoDecodeUntil(wrapped, 5, flagfmt.Found(""))
+	if string(got) != string(inner) || len(trail) != 1 {
+		t.Fatalf("AutoDecodeUntil = %q %v, want %q after one round", got, trail, inner)
+	}
+}
+
+func TestPrintable(t *testing.T) {
+	if got := Printable([]byte("ab\x00\xff")); got != 0.5 {
+		t.Errorf("Printable = %v, want 0.5", got)
+	}
+	if got := Printable(nil); got != 0 {
+		t.Errorf("Printable(nil) = %v, want 0", got)
+	}
+}
@@ -0,0 +1,84 @@
+package decode
+
+import (
+	"fmt"
+	"strings"
+)
+
+// morse is the international Morse code table.
+var morse = map[string]byte{
+	".-": 'A', "-...": 'B', "-.-.": 'C', "-..": 'D', ".": 'E', "..-.": 'F',
+	"--.": 'G', "....": 'H', "..": 'I', ".---": 'J', "-.-": 'K', ".-..": 'L',
+	"--": 'M', "-.": 'N', "---": 'O', ".--.": 'P', "--.-": 'Q', ".-.": 'R',
+	"...": 'S', "-": 'T', "..-": 'U', "...-": 'V', ".--": 'W', "-..-": 'X',
+	"-.--": 'Y', "--..": 'Z',
+	"-----": '0', ".----": '1', "..---": '2', "...--": '3', "....-": '4',
+	".....": '5', "-....": '6', "--...": '7', "---..": '8', "----.": '9',
+	".-.-.-": '.', "--..--": ',', "..--..": '?', ".----.": '\'', "-.-.--": '!',
+	"-..-.": '/', "-.--.": '(', "-.--.-": ')', ".-...": '&', "---...": ':',
+	"-.-.-.": ';', "-...-": '=', ".-.-.": '+', "-....-": '-', "..--.-": '_',
+	".-..-.": '"', "...-..-": '$', ".--.-.": '@',
+}
+
+// Morse decodes dot-dash Morse code with letters separated by a space and
+// words by " / " or two or more spaces. Letters come out in upper case.
+// Symbols not in the table decode as '?' and are listed in the error.
+func Morse(s string) (string, error) {
+	s = strings.NewReplacer("\r", " ", "\n", "  ", "\t", " ", "/", " / ").Replace(s)
+	var (
+		out     strings.Builder
+		unknown []string
+		gap     bool
+	)
+	for _, tok := range strings.Split(strings.TrimSpace(s), " ") {
+		if tok == "" || tok == "/" {
+			gap = true
+			continue
+		}
+		if gap && out.Len() > 0 {
+			out.WriteByte(' ')
+		}
+		gap = false
+		if c, ok := morse[tok]; ok {
+			out.WriteByte(c)
+		} else {
+			out.WriteByte('?')
+			unknown = append(unknown, tok)
+		}
+	}
+	if unknown != nil {
+		return out.String(), fmt.Errorf("unknown morse symbols %q", unknown)
+	}
+	return out.String(), nil
+}
+
+// BinaryASCII turns groups of binary digits, such as "01100110 01101100",
+// into the bytes they spell. Groups may be separated by whitespace or
+// commas; an unbroken run of bits is split every 8 digits.
+func BinaryASCII(s string) (string, error) {
+	groups := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == ','
+	})
+	if len(groups) == 1 && len(groups[0]) > 8 && len(groups[0])%8 == 0 {
+		run := groups[0]
+		groups = groups[:0]
+		for i := 0; i < len(run); i += 8 {
+			groups = append(groups, run[i:i+8])
+		}
+	}
+	out := make([]byte, 0, len(groups))
+	for _, g := range groups {
+		if len(g) > 8 {
+			return string(out), fmt.Errorf("binary group %q is longer than 8 bits", g)
+		}
+		var b byte
+		for i := 0; i < len(g); i++ {
+			if g[i] != '0' && g[i] != '1' {
+				return string(out), fmt.Errorf("invalid binary digit %q in group %q", g[i], g)
+			}
+			b = b<<1 | (g[i] - '0')
+		}
+		out = append(out, b)
+	}
+	return string(out), nil
+}
@@ -0,0 +1,54 @@
+package decode
+
+import "testing"
+
+func TestMorseFlag(t *testing.T) {
+	for _, in := range []string{
+		"..-. .-.. .- --. / -- ----- .-. ... ...-- ..--.- .-. ----- -.-. -.- ...",
+		"..-. .-.. .- --.  -- ----- .-. ... ...-- ..--.- .-. ----- -.-. -.- ...\n",
+		"..-. .-.. .- --./-- ----- .-. ... ...-- ..--.- .-. ----- -.-. -.- ...",
+		"..-. .-.. .- --.\n-- ----- .-. ... ...-- ..--.- .-. ----- -.-. -.- ...",
+	} {
+		got, err := Morse(in)
+		if err != nil || got != "FLAG M0RS3_R0CKS" {
+			t.Errorf("Morse(%q) = %q, %v", in, got, err)
+		}
+	}
+}
+
+func TestMorseUnknownSymbols(t *testing.T) {
+	got, err := Morse("... ........ ...")
+	if got != "S?S" {
+		t.Errorf("Morse = %q, want S?S", got)
+	}
+	if err == nil || err.Error() != `unknown morse symbols ["........"]` {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestBinaryASCIIFlag(t *testing.T) {
+	const spaced = "01100110 01101100 01100001 01100111 01111011 01100010 00110001 01101110 01111101"
+	for _, in := range []string{
+		spaced,
+		"01100110,01101100,01100001,01100111,01111011,01100010,00110001,01101110,01111101\n",
+		"011001100110110001100001011001110111101101100010001100010110111001111101",
+		"1100110 1101100 1100001 1100111 1111011 1100010 110001 1101110 1111101",
+	} {
+		got, err := BinaryASCII(in)
+		if err != nil || got != "flag{b1n}" {
+			t.Errorf("BinaryASCII(%q) = %q, %v", in, got, err)
+		}
+	}
+}
+
+func TestBinaryASCIIErrors(t *testing.T) {
+	for _, tc := range []struct{ in, partial, want string }{
+		{"01100110 011011000", "f", `binary group "011011000" is longer than 8 bits`},
+		{"01100110 0110a100", "f", `invalid binary digit 'a' in group "0110a100"`},
+	} {
+		got, err := BinaryASCII(tc.in)
+		if got != tc.partial || err == nil || err.Error() != tc.want {
+			t.Errorf("BinaryASCII(%q) = %q, %v; want %q, %q", tc.in, got, err, tc.partial, tc.want)
+		}
+	}
+}
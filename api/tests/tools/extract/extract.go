@@ -0,0 +1,43 @@
+// Package extract pulls printable strings out of binary data the way
+// strings(1) does, for the tools that dig through files and captures.
+package extract
+
+// Run is one printable run and its byte offset in the data.
+type Run struct {
+	Offset int
+	S      string
+}
+
+func printable(c byte) bool {
+	return c >= 0x20 && c < 0x7f || c == '\t'
+}
+
+// Strings returns every run of at least min printable ASCII characters in
+// data. Line ends break runs, so each line of a text protocol stands alone.
+// With utf16 set it looks for UTF-16LE text instead, each character a
+// printable byte followed by a zero byte, as Windows binaries store it;
+// runs may start at odd offsets.
+func Strings(data []byte, min int, utf16 bool) []Run {
+	var out []Run
+	width := 1
+	if utf16 {
+		width = 2
+	}
+	for i := 0; i < len(data); {
+		j := i
+		var run []byte
+		for j+width <= len(data) && printable(data[j]) && (!utf16 || data[j+1] == 0) {
+			run = append(run, data[j])
+			j += width
+		}
+		if len(run) == 0 {
+			i++
+			continue
+		}
+		if len(run) >= min {
+			out = append(out, Run{Offset: i, S: string(run)})
+		}
+		i = j
+	}
+	return out
+}
@@ -0,0 +1,50 @@
+package extract
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStringsThreshold(t *testing.T) {
+	data := []byte("abc\x00abcd\x01\x02flag{x}\r\nok\n\tindented\xff")
+	for _, tc := range []struct {
+		min  int
+		want []Run
+	}{
+		{4, []Run{{4, "abcd"}, {10, "flag{x}"}, {22, "\tindented"}}},
+		{3, []Run{{0, "abc"}, {4, "abcd"}, {10, "flag{x}"}, {22, "\tindented"}}},
+		{8, []Run{{22, "\tindented"}}},
+		{2, []Run{{0, "abc"}, {4, "abcd"}, {10, "flag{x}"}, {19, "ok"}, {22, "\tindented"}}},
+	} {
+		if got := Strings(data, tc.min, false); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Strings(min %d) = %v, want %v", tc.min, got, tc.want)
+		}
+	}
+}
+
+func TestStringsUTF16LE(t *testing.T) {
+	// "C:\Flag" in UTF-16LE at an odd offset, then a run too short to
+	// report and a lone ASCII string that is not UTF-16.
+	data := []byte("\xff" +
+		"C\x00:\x00\\\x00F\x00l\x00a\x00g\x00" +
+		"\x00\x00" +
+		"o\x00k\x00" +
+		"\x00\x00plain ascii")
+	want := []Run{{1, `C:\Flag`}}
+	if got := Strings(data, 4, true); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Strings(utf16) = %v, want %v", got, want)
+	}
+	if got := Strings(data, 4, false); !reflect.DeepEqual(got, []Run{{23, "plain ascii"}}) {
+		t.Fatalf("Strings(ascii) = %v", got)
+	}
+}
+
+func TestStringsEmpty(t *testing.T) {
+	if got := Strings(nil, 1, false); got != nil {
+		t.Fatalf("Strings(nil) = %v", got)
+	}
+	// A trailing odd byte can't start a UTF-16 character.
+	if got := Strings([]byte("a\x00b"), 1, true); !reflect.DeepEqual(got, []Run{{0, "a"}}) {
+		t.Fatalf("Strings(utf16 odd tail) = %v", got)
+	}
+}
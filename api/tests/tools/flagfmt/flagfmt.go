@@ -0,0 +1,79 @@
+// Package flagfmt finds CTF flags such as flag{...} or HTB{...} in
+// arbitrary output.
+package flagfmt
+
+import (
+	"regexp"
+	"strings"
+)
+
+// body matches a flag's contents: printable ASCII other than '}', so a
+// stray brace in binary data doesn't swallow the bytes after it.
+const body = `\{[\x20-\x7c\x7e]+\}`
+
+// DefaultPattern matches the common prefix{body} flag shape.
+const DefaultPattern = `[A-Za-z0-9_]+` + body
+
+// PrefixPattern builds a pattern for a CTF's own flag prefix, with or
+// without the opening brace: PrefixPattern("HTB") and PrefixPattern("HTB{")
+// both match HTB{...}.
+func PrefixPattern(prefix string) string {
+	return regexp.QuoteMeta(strings.TrimSuffix(prefix, "{")) + body
+}
+
+// compile returns the regexp for pattern, using DefaultPattern when it is
+// empty and PrefixPattern when it is a bare prefix ending in '{'.
+func compile(pattern string) (*regexp.Regexp, error) {
+	switch {
+	case pattern == "":
+		pattern = DefaultPattern
+	case strings.HasSuffix(pattern, "{"):
+		pattern = PrefixPattern(pattern)
+	}
+	return regexp.Compile(pattern)
+}
+
+// FindFlags returns the distinct matches of pattern in data in the order
+// they first appear. An empty pattern means DefaultPattern and one ending
+// in '{', such as HTB{, matches flags with that prefix; an invalid pattern
+// finds nothing.
+func FindFlags(data []byte, pattern string) []string {
+	re, err := compile(pattern)
+	if err != nil {
+		return nil
+	}
+	return find(re, data)
+}
+
+func find(re *regexp.Regexp, data []byte) []string {
+	var flags []string
+	seen := make(map[string]bool)
+	for _, m := range re.FindAll(data, -1) {
+		if s := string(m); !seen[s] {
+			seen[s] = true
+			flags = append(flags, s)
+		}
+	}
+	return flags
+}
+
+// Highlight wraps every match of pattern in data with ANSI bold red so
+// recovered flags stand out in tool output. Data is returned unchanged if
+// the pattern is invalid.
+func Highlight(data []byte, pattern string) []byte {
+	re, err := compile(pattern)
+	if err != nil {
+		return data
+	}
+	return re.ReplaceAll(data, []byte("\x1b[1;31m$0\x1b[0m"))
+}
+
+// Found returns a predicate reporting whether data contains a flag, in the
+// shape decode.AutoDecodeUntil takes as its stop condition.
+func Found(pattern string) func([]byte) bool {
+	re, err := compile(pattern)
+	if err != nil {
+		return func([]byte) bool { return false }
+	}
+	return re.Match
+}
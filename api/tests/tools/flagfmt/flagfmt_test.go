@@ -0,0 +1,67 @@
+package flagfmt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindFlags(t *testing.T) {
+	out := []byte("junk flag{one} more HTB{two_2} \x00\xffCTF{one}flag{one} picoCTF{th3_end}")
+	got := FindFlags(out, "")
+	want := []string{"flag{one}", "HTB{two_2}", "CTF{one}", "picoCTF{th3_end}"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("FindFlags = %q, want %q", got, want)
+	}
+}
+
+func TestFindFlagsStopsAtBrace(t *testing.T) {
+	got := FindFlags([]byte("flag{a} trailing} text"), "")
+	if want := []string{"flag{a}"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("FindFlags = %q, want %q", got, want)
+	}
+	if got := FindFlags([]byte("flag{}"), ""); got != nil {
+		t.Fatalf("FindFlags(empty body) = %q, want none", got)
+	}
+}
+
+func TestPrefixPattern(t *testing.T) {
+	out := []byte("flag{decoy} HTB{real} htb{case}")
+	for _, prefix := range []string{"HTB", "HTB{"} {
+		got := FindFlags(out, PrefixPattern(prefix))
+		if want := []string{"HTB{real}"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("FindFlags(PrefixPattern(%q)) = %q, want %q", prefix, got, want)
+		}
+	}
+	if got := FindFlags(out, "HTB{"); !reflect.DeepEqual(got, []string{"HTB{real}"}) {
+		t.Errorf("FindFlags(\"HTB{\") = %q, want the bare prefix treated as PrefixPattern", got)
+	}
+	if got := FindFlags([]byte("a.b{x} aXb{y}"), PrefixPattern("a.b")); !reflect.DeepEqual(got, []string{"a.b{x}"}) {
+		t.Errorf("prefix metacharacters not quoted: %q", got)
+	}
+}
+
+func TestInvalidPattern(t *testing.T) {
+	if got := FindFlags([]byte("flag{x}"), "("); got != nil {
+		t.Errorf("FindFlags(invalid) = %q, want nil", got)
+	}
+	if Found("(")([]byte("flag{x}")) {
+		t.Error("Found(invalid) matched")
+	}
+	if got := Highlight([]byte("flag{x}"), "("); string(got) != "flag{x}" {
+		t.Errorf("Highlight(invalid) = %q, want input unchanged", got)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	got := Highlight([]byte("got flag{x}!"), "")
+	if want := "got \x1b[1;31mflag{x}\x1b[0m!"; string(got) != want {
+		t.Fatalf("Highlight = %q, want %q", got, want)
+	}
+}
+
+func TestFound(t *testing.T) {
+	found := Found("")
+	if !found([]byte("...flag{x}...")) || found([]byte("no flag here")) {
+		t.Fatal("Found misclassified its input")
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/dnakov/ctf-arena/api/tests/tools
+
+go 1.23
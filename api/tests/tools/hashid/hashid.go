@@ -0,0 +1,71 @@
+// Package hashid guesses the algorithm behind a hash string from its
+// prefix, length and character set.
+package hashid
+
+import "strings"
+
+// prefixed lists formats that announce themselves with a prefix, most
+// specific first. They are certain enough to outrank length-only guesses.
+var prefixed = []struct{ prefix, name string }{
+	{"$2a$", "bcrypt"},
+	{"$2b$", "bcrypt"},
+	{"$2x$", "bcrypt"},
+	{"$2y$", "bcrypt"},
+	{"$argon2id$", "Argon2id"},
+	{"$argon2i$", "Argon2i"},
+	{"$argon2d$", "Argon2d"},
+	{"$apr1$", "Apache MD5 (apr1)"},
+	{"$1$", "md5crypt"},
+	{"$5$", "sha256crypt"},
+	{"$6$", "sha512crypt"},
+	{"$y$", "yescrypt"},
+	{"$P$", "phpass (WordPress)"},
+	{"$H$", "phpass (phpBB)"},
+	{"{SSHA512}", "LDAP salted SHA-512"},
+	{"{SSHA}", "LDAP salted SHA-1"},
+	{"{SHA}", "LDAP SHA-1"},
+	{"pbkdf2_sha256$", "Django PBKDF2-SHA256"},
+}
+
+// byHexLen lists plain hex digests by length, the most likely first.
+var byHexLen = map[int][]string{
+	8:   {"CRC32", "Adler-32"},
+	16:  {"MySQL 3.x", "CRC64", "half MD5"},
+	32:  {"MD5", "NTLM", "MD4", "LM"},
+	40:  {"SHA-1", "RIPEMD-160"},
+	56:  {"SHA-224", "SHA3-224"},
+	64:  {"SHA-256", "SHA3-256", "BLAKE2s-256"},
+	96:  {"SHA-384", "SHA3-384"},
+	128: {"SHA-512", "SHA3-512", "BLAKE2b-512", "Whirlpool"},
+}
+
+func isHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// Identify returns the hash types s could be, most likely first, or nil if
+// nothing fits. Prefixed formats such as bcrypt's $2a$ come before guesses
+// based on the length of a hex digest alone.
+func Identify(s string) []string {
+	s = strings.TrimSpace(s)
+	var out []string
+	for _, p := range prefixed {
+		if strings.HasPrefix(s, p.prefix) {
+			out = append(out, p.name)
+			break
+		}
+	}
+	if len(s) == 41 && s[0] == '*' && isHex(s[1:]) {
+		out = append(out, "MySQL 4.1+")
+	}
+	if isHex(s) {
+		out = append(out, byHexLen[len(s)]...)
+	}
+	return out
+}
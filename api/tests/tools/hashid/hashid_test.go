@@ -0,0 +1,49 @@
+package hashid
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIdentify(t *testing.T) {
+	for _, tc := range []struct {
+		hash string
+		want []string
+	}{
+		{"cbf43926", []string{"CRC32", "Adler-32"}},
+		{"5f4dcc3b5aa765d61d8327deb882cf99", []string{"MD5", "NTLM", "MD4", "LM"}},
+		{"5F4DCC3B5AA765D61D8327DEB882CF99", []string{"MD5", "NTLM", "MD4", "LM"}},
+		{"5baa61e4c9b93f3f0682250b6cf8331b7ee68fd8", []string{"SHA-1", "RIPEMD-160"}},
+		{"5e884898da28047151d0e56f8dc6292773603d0d6aabbdd62a11ef721d1542d8", []string{"SHA-256", "SHA3-256", "BLAKE2s-256"}},
+		{strings.Repeat("ab", 64), []string{"SHA-512", "SHA3-512", "BLAKE2b-512", "Whirlpool"}},
+		{"  5baa61e4c9b93f3f0682250b6cf8331b7ee68fd8\n", []string{"SHA-1", "RIPEMD-160"}},
+		{"$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy", []string{"bcrypt"}},
+		{"$2y$12$QjSH496pcT5CEbzjD/vtVeH03tfHKFy36d4J0Ltp3lRtee9HDxY3K", []string{"bcrypt"}},
+		{"$6$saltsalt$qFmFH.bQmmtXzyBY0s9v7Oicd2z4XSIecDzlB5KiA2/jctKu9YterLp8wwnSq.qc.eoxqOmSuNp2xS0ktL3nh/", []string{"sha512crypt"}},
+		{"$argon2id$v=19$m=65536,t=3,p=4$c2FsdHNhbHQ$aGFzaA", []string{"Argon2id"}},
+		{"{SSHA}2c2W5m7b6Mcb1J6pc1Mn0uHRYDPWD62I", []string{"LDAP salted SHA-1"}},
+		{"*2470C0C06DEE42FD1618BB99005ADCA2EC9D1E19", []string{"MySQL 4.1+"}},
+		{"5f4dcc3b5aa765d61d8327deb882cf9", nil},
+		{"not a hash", nil},
+		{"", nil},
+	} {
+		if got := Identify(tc.hash); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Identify(%q) = %q, want %q", tc.hash, got, tc.want)
+		}
+	}
+}
+
+func TestIdentifySpecificPrefixFirst(t *testing.T) {
+	// $argon2id$ must not be reported as the shorter $argon2i$, nor
+	// {SSHA512} as {SSHA}.
+	for hash, want := range map[string]string{
+		"$argon2i$v=19$m=4096,t=3,p=1$c2FsdA$aGFzaA":  "Argon2i",
+		"$argon2id$v=19$m=4096,t=3,p=1$c2FsdA$aGFzaA": "Argon2id",
+		"{SSHA512}aGFzaGhhc2hzYWx0":                   "LDAP salted SHA-512",
+	} {
+		if got := Identify(hash); !reflect.DeepEqual(got, []string{want}) {
+			t.Errorf("Identify(%q) = %q, want %q", hash, got, want)
+		}
+	}
+}
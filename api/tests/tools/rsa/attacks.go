@@ -0,0 +1,90 @@
+// Package rsa implements the textbook-RSA attacks that CTF crypto rounds
+// are built around.
+package rsa
+
+import (
+	"errors"
+	"math/big"
+)
+
+// maxWraps bounds how many multiples of n CubeRootAttack adds to c when
+// m^e only slightly exceeds the modulus.
+const maxWraps = 1 << 12
+
+var bigOne = big.NewInt(1)
+
+// iroot returns the integer k-th root of x (floor) using Newton's method.
+func iroot(x *big.Int, k int) *big.Int {
+	if x.Sign() == 0 {
+		return new(big.Int)
+	}
+	kb := big.NewInt(int64(k))
+	k1 := big.NewInt(int64(k - 1))
+	// Start above the root: 2^ceil(bits/k).
+	r := new(big.Int).Lsh(bigOne, uint(x.BitLen()/k+1))
+	for {
+		// next = ((k-1)*r + x / r^(k-1)) / k
+		pow := new(big.Int).Exp(r, k1, nil)
+		next := new(big.Int).Mul(k1, r)
+		next.Add(next, new(big.Int).Quo(x, pow))
+		next.Quo(next, kb)
+		if next.Cmp(r) >= 0 {
+			return r
+		}
+		r = next
+	}
+}
+
+// CubeRootAttack recovers an unpadded message encrypted with a small public
+// exponent (typically e=3), where m^e barely wraps the modulus or not at
+// all, by taking the exact integer e-th root of c + k*n for small k.
+func CubeRootAttack(c, e, n *big.Int) ([]byte, error) {
+	if !e.IsInt64() || e.Int64() < 2 || e.Int64() > 1<<16 {
+		return nil, errors.New("rsa: exponent too large for a root attack")
+	}
+	k := int(e.Int64())
+	x := new(big.Int).Set(c)
+	for i := 0; i < maxWraps; i++ {
+		m := iroot(x, k)
+		if new(big.Int).Exp(m, e, nil).Cmp(x) == 0 {
+			return m.Bytes(), nil
+		}
+		if n == nil || n.Sign() == 0 {
+			break
+		}
+		x.Add(x, n)
+	}
+	return nil, errors.New("rsa: ciphertext has no exact integer root; message is padded or too large")
+}
+
+// CommonModulus recovers m from the same message encrypted under one
+// modulus n with two coprime exponents: with a*e1 + b*e2 = 1 from the
+// extended Euclidean algorithm, m = c1^a * c2^b mod n.
+func CommonModulus(c1, c2, e1, e2, n *big.Int) ([]byte, error) {
+	a, b := new(big.Int), new(big.Int)
+	if g := new(big.Int).GCD(a, b, e1, e2); g.Cmp(bigOne) != 0 {
+		return nil, errors.New("rsa: exponents are not coprime")
+	}
+	m1, err := powMod(c1, a, n)
+	if err != nil {
+		return nil, err
+	}
+	m2, err := powMod(c2, b, n)
+	if err != nil {
+		return nil, err
+	}
+	m := new(big.Int).Mul(m1, m2)
+	return m.Mod(m, n).Bytes(), nil
+}
+
+// powMod computes c^x mod n, inverting c first when x is negative.
+func powMod(c, x, n *big.Int) (*big.Int, error) {
+	if x.Sign() >= 0 {
+		return new(big.Int).Exp(c, x, n), nil
+	}
+	inv := new(big.Int).ModInverse(c, n)
+	if inv == nil {
+		return nil, errors.New("rsa: ciphertext is not invertible mod n")
+	}
+	return new(big.Int).Exp(inv, new(big.Int).Neg(x), n), nil
+}
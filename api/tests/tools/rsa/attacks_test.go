@@ -0,0 +1,90 @@
+package rsa
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+// modulus returns the product of two fresh primes of bits bits each.
+func modulus(t *testing.T, bits int) *big.Int {
+	t.Helper()
+	p, err := rand.Prime(rand.Reader, bits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := rand.Prime(rand.Reader, bits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return new(big.Int).Mul(p, q)
+}
+
+func TestCubeRootAttackNoWrap(t *testing.T) {
+	n := modulus(t, 512)
+	e := big.NewInt(3)
+	msg := []byte("flag{cube_r00ts_are_easy}")
+	c := new(big.Int).Exp(new(big.Int).SetBytes(msg), e, n)
+
+	got, err := CubeRootAttack(c, e, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(msg) {
+		t.Fatalf("CubeRootAttack = %q, want %q", got, msg)
+	}
+}
+
+func TestCubeRootAttackWrapped(t *testing.T) {
+	n := modulus(t, 128)
+	e := big.NewInt(3)
+	// m^3 is about five times n, so it wraps the modulus a few times.
+	m := iroot(new(big.Int).Mul(n, big.NewInt(5)), 3)
+	m.Add(m, big.NewInt(7))
+	c := new(big.Int).Exp(m, e, n)
+
+	got, err := CubeRootAttack(c, e, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if new(big.Int).SetBytes(got).Cmp(m) != 0 {
+		t.Fatalf("CubeRootAttack = %x, want %x", got, m.Bytes())
+	}
+}
+
+func TestCubeRootAttackPadded(t *testing.T) {
+	n := modulus(t, 256)
+	// A full-size message wraps far more often than the attack tries.
+	m := new(big.Int).Sub(n, big.NewInt(12345))
+	c := new(big.Int).Exp(m, big.NewInt(3), n)
+	if _, err := CubeRootAttack(c, big.NewInt(3), n); err == nil {
+		t.Fatal("CubeRootAttack recovered a full-size message")
+	}
+}
+
+func TestCommonModulus(t *testing.T) {
+	n := modulus(t, 512)
+	msg := []byte("flag{sh4red_m0dulus}")
+	m := new(big.Int).SetBytes(msg)
+	e1, e2 := big.NewInt(17), big.NewInt(65537)
+	c1 := new(big.Int).Exp(m, e1, n)
+	c2 := new(big.Int).Exp(m, e2, n)
+
+	got, err := CommonModulus(c1, c2, e1, e2, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(msg) {
+		t.Fatalf("CommonModulus = %q, want %q", got, msg)
+	}
+	if _, err := CommonModulus(c1, c2, big.NewInt(6), big.NewInt(9), n); err == nil {
+		t.Fatal("CommonModulus accepted exponents sharing a factor")
+	}
+}
+
+func TestLeadingZerosStripped(t *testing.T) {
+	got, err := CubeRootAttack(big.NewInt(27), big.NewInt(3), nil)
+	if err != nil || len(got) != 1 || got[0] != 3 {
+		t.Fatalf("CubeRootAttack(27) = %x, %v; want 03", got, err)
+	}
+}